@@ -0,0 +1,73 @@
+package main
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+)
+
+// withAPIKey rejects mutations that do not carry apiKey, either as
+// "Authorization: Bearer <key>" or in the X-API-Key header. Queries stay
+// open. An empty apiKey disables the check.
+func withAPIKey(next http.Handler, apiKey string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKey == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		req, err := peekRequest(r)
+		if err != nil {
+			// let serveGraphQL report the malformed body
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if hasOperation(req.Query, ast.OperationTypeMutation) && !validAPIKey(r, apiKey) {
+			writeGraphQLError(w, http.StatusUnauthorized, "a valid API key is required for mutations")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withWriteAPIKey is the REST counterpart of withAPIKey: every method but
+// GET and HEAD needs apiKey.
+func withWriteAPIKey(next http.Handler, apiKey string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		readOnly := r.Method == http.MethodGet || r.Method == http.MethodHead
+		if apiKey != "" && !readOnly && !validAPIKey(r, apiKey) {
+			writeJSONError(w, http.StatusUnauthorized, "a valid API key is required")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// withAdminKey marks requests whose X-Admin-Key header matches adminKey as
+// admin requests. An empty adminKey leaves admin fields locked.
+func withAdminKey(next http.Handler, adminKey string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("X-Admin-Key")
+		if adminKey != "" && subtle.ConstantTimeCompare([]byte(key), []byte(adminKey)) == 1 {
+			r = r.WithContext(graph.WithAdmin(r.Context()))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func validAPIKey(r *http.Request, apiKey string) bool {
+	key := r.Header.Get("X-API-Key")
+	if auth := r.Header.Get("Authorization"); key == "" && strings.HasPrefix(auth, "Bearer ") {
+		key = strings.TrimPrefix(auth, "Bearer ")
+	}
+
+	return subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestAPIKey(t *testing.T) {
+	h := withAPIKey(serveGraphQL(newSchema(t, store.NewMemoryStore()), time.Second), "secret")
+	const mutation = `mutation { createTodo(Text: "a") { Id } }`
+
+	for _, tc := range []struct {
+		name   string
+		query  string
+		header string
+		value  string
+		status int
+	}{
+		{"mutation with X-API-Key", mutation, "X-API-Key", "secret", http.StatusOK},
+		{"mutation with bearer token", mutation, "Authorization", "Bearer secret", http.StatusOK},
+		{"mutation without a key", mutation, "", "", http.StatusUnauthorized},
+		{"mutation with a wrong key", mutation, "X-API-Key", "guess", http.StatusUnauthorized},
+		{"query without a key", `{ todoList { Id } }`, "", "", http.StatusOK},
+	} {
+		r := newQueryPost(tc.query)
+		if tc.header != "" {
+			r.Header.Set(tc.header, tc.value)
+		}
+		w := serve(h, r)
+		if w.Code != tc.status {
+			t.Errorf("%s: status %d, want %d", tc.name, w.Code, tc.status)
+			continue
+		}
+		if res := decodeResponse(t, w); tc.status == http.StatusOK && len(res.Errors) > 0 {
+			t.Errorf("%s: errors %+v", tc.name, res.Errors)
+		}
+	}
+}
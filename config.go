@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+const (
+	defaultDatabaseDriver = "sqlite3"
+	defaultDatabaseDSN    = "./test.db"
+)
+
+// config holds the server settings read from the environment.
+type config struct {
+	// DatabaseDriver and DatabaseDSN select the xorm engine. postgres and
+	// mysql need the binary built with the matching build tag.
+	DatabaseDriver string
+	DatabaseDSN    string
+	// DatabaseRetry says how often and how patiently writes are retried
+	// while the database is locked.
+	DatabaseRetry store.Retry
+
+	// RequestTimeout bounds how long a single GraphQL request may run.
+	RequestTimeout time.Duration
+	// QueryCacheTTL is how long responses of read-only queries are reused;
+	// 0 turns the cache off.
+	QueryCacheTTL time.Duration
+	// LogQueries adds the query text to the request log. Off by default
+	// because queries may carry sensitive todo text.
+	LogQueries bool
+	// LogSQL logs every SQL statement with its arguments and duration.
+	// Off by default for the same reason as LogQueries.
+	LogSQL bool
+	// RateLimit is how many requests per second a client IP may make on
+	// average, with bursts of up to RateLimitBurst; 0 disables limiting.
+	RateLimit      float64
+	RateLimitBurst int
+	// TrustProxy takes the client IP for rate and connection limits from
+	// the X-Forwarded-For header the proxy in front of the server sets.
+	TrustProxy bool
+	// MaxBodyBytes caps the size of request bodies; 0 disables the cap.
+	MaxBodyBytes int64
+	// MaxQueryDepth caps how deeply a query's selections may nest; 0
+	// disables the check.
+	MaxQueryDepth int
+	// MaxSubscriptions caps the open subscription connections, and
+	// MaxSubscriptionsPerIP those of one client IP; 0 disables a cap.
+	MaxSubscriptions      int
+	MaxSubscriptionsPerIP int
+	// APIKey is required on mutations when set.
+	APIKey string
+	// AdminKey unlocks the admin-only fields when sent as X-Admin-Key.
+	AdminKey string
+	// GraphiQL serves the GraphiQL UI at /. Turn it off in production.
+	GraphiQL bool
+	// Seed fills an empty database with the demo todos on startup.
+	Seed bool
+	// UniqueText stops a user from creating two todos with the same text.
+	UniqueText bool
+	// Transitions overrides the allowed status transitions when set.
+	Transitions model.Transitions
+}
+
+func loadConfig() (config, error) {
+	cfg := config{
+		DatabaseDriver: envString("DATABASE_DRIVER", defaultDatabaseDriver),
+		DatabaseDSN:    envString("DATABASE_DSN", defaultDatabaseDSN),
+	}
+	var err error
+
+	if cfg.DatabaseRetry.Attempts, err = envInt("DATABASE_BUSY_RETRIES", store.DefaultRetry.Attempts); err != nil {
+		return cfg, err
+	}
+	if cfg.DatabaseRetry.Backoff, err = envDuration("DATABASE_BUSY_BACKOFF", store.DefaultRetry.Backoff); err != nil {
+		return cfg, err
+	}
+
+	if cfg.RequestTimeout, err = envDuration("REQUEST_TIMEOUT", 10*time.Second); err != nil {
+		return cfg, err
+	}
+	if cfg.RequestTimeout <= 0 {
+		return cfg, fmt.Errorf("REQUEST_TIMEOUT must be positive, got %s", cfg.RequestTimeout)
+	}
+
+	if cfg.QueryCacheTTL, err = envDuration("QUERY_CACHE_TTL", 0); err != nil {
+		return cfg, err
+	}
+
+	if cfg.LogQueries, err = envBool("LOG_QUERY", false); err != nil {
+		return cfg, err
+	}
+	if cfg.LogSQL, err = envBool("LOG_SQL", false); err != nil {
+		return cfg, err
+	}
+
+	if cfg.MaxQueryDepth, err = envInt("MAX_QUERY_DEPTH", 10); err != nil {
+		return cfg, err
+	}
+
+	if cfg.MaxSubscriptions, err = envInt("MAX_SUBSCRIPTIONS", 1000); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxSubscriptions < 0 {
+		return cfg, fmt.Errorf("MAX_SUBSCRIPTIONS must not be negative, got %d", cfg.MaxSubscriptions)
+	}
+	if cfg.MaxSubscriptionsPerIP, err = envInt("MAX_SUBSCRIPTIONS_PER_IP", 10); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxSubscriptionsPerIP < 0 {
+		return cfg, fmt.Errorf("MAX_SUBSCRIPTIONS_PER_IP must not be negative, got %d", cfg.MaxSubscriptionsPerIP)
+	}
+
+	if cfg.RateLimit, err = envFloat("RATE_LIMIT", 0); err != nil {
+		return cfg, err
+	}
+	if cfg.RateLimitBurst, err = envInt("RATE_LIMIT_BURST", 20); err != nil {
+		return cfg, err
+	}
+	if cfg.RateLimitBurst < 1 {
+		return cfg, fmt.Errorf("RATE_LIMIT_BURST must be at least 1, got %d", cfg.RateLimitBurst)
+	}
+	if cfg.TrustProxy, err = envBool("TRUST_PROXY", false); err != nil {
+		return cfg, err
+	}
+
+	maxBodyBytes, err := envInt("MAX_BODY_BYTES", 1<<20)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.MaxBodyBytes = int64(maxBodyBytes)
+
+	if cfg.Seed, err = envBool("SEED", false); err != nil {
+		return cfg, err
+	}
+
+	if cfg.UniqueText, err = envBool("UNIQUE_TEXT", false); err != nil {
+		return cfg, err
+	}
+
+	if cfg.GraphiQL, err = envBool("ENABLE_GRAPHIQL", true); err != nil {
+		return cfg, err
+	}
+
+	cfg.APIKey = os.Getenv("API_KEY")
+	cfg.AdminKey = os.Getenv("ADMIN_API_KEY")
+
+	if v := os.Getenv("STATUS_TRANSITIONS"); v != "" {
+		if cfg.Transitions, err = model.ParseTransitions(v); err != nil {
+			return cfg, fmt.Errorf("STATUS_TRANSITIONS: %v", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// settings lists the configuration for the config query. Keys and the
+// DSN, which may hold a password, only show whether they are set.
+func (c config) settings() []graph.Setting {
+	transitions := c.Transitions
+	if transitions == nil {
+		transitions = model.DefaultTransitions()
+	}
+
+	return []graph.Setting{
+		{Name: "DATABASE_DRIVER", Value: c.DatabaseDriver},
+		{Name: "DATABASE_DSN", Value: redacted(c.DatabaseDSN)},
+		{Name: "DATABASE_BUSY_RETRIES", Value: strconv.Itoa(c.DatabaseRetry.Attempts)},
+		{Name: "DATABASE_BUSY_BACKOFF", Value: c.DatabaseRetry.Backoff.String()},
+		{Name: "REQUEST_TIMEOUT", Value: c.RequestTimeout.String()},
+		{Name: "QUERY_CACHE_TTL", Value: c.QueryCacheTTL.String()},
+		{Name: "RATE_LIMIT", Value: strconv.FormatFloat(c.RateLimit, 'g', -1, 64)},
+		{Name: "RATE_LIMIT_BURST", Value: strconv.Itoa(c.RateLimitBurst)},
+		{Name: "TRUST_PROXY", Value: strconv.FormatBool(c.TrustProxy)},
+		{Name: "MAX_BODY_BYTES", Value: strconv.FormatInt(c.MaxBodyBytes, 10)},
+		{Name: "MAX_QUERY_DEPTH", Value: strconv.Itoa(c.MaxQueryDepth)},
+		{Name: "MAX_SUBSCRIPTIONS", Value: strconv.Itoa(c.MaxSubscriptions)},
+		{Name: "MAX_SUBSCRIPTIONS_PER_IP", Value: strconv.Itoa(c.MaxSubscriptionsPerIP)},
+		{Name: "STATUS_TRANSITIONS", Value: transitions.String()},
+		{Name: "API_KEY", Value: redacted(c.APIKey)},
+		{Name: "ADMIN_API_KEY", Value: redacted(c.AdminKey)},
+		{Name: "ENABLE_GRAPHIQL", Value: strconv.FormatBool(c.GraphiQL)},
+		{Name: "SEED", Value: strconv.FormatBool(c.Seed)},
+		{Name: "UNIQUE_TEXT", Value: strconv.FormatBool(c.UniqueText)},
+		{Name: "LOG_QUERY", Value: strconv.FormatBool(c.LogQueries)},
+		{Name: "LOG_SQL", Value: strconv.FormatBool(c.LogSQL)},
+	}
+}
+
+// redacted hides a secret, only telling whether it is set.
+func redacted(secret string) string {
+	if secret == "" {
+		return "unset"
+	}
+	return "set"
+}
+
+// envString returns the named variable, or def when it is unset.
+func envString(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// envDuration parses the named variable as a time.Duration, falling back
+// to def when it is unset.
+func envDuration(name string, def time.Duration) (time.Duration, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return d, nil
+}
+
+// envInt parses the named variable as an integer, falling back to def when
+// it is unset.
+func envInt(name string, def int) (int, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return n, nil
+}
+
+// envFloat parses the named variable as a floating point number, falling
+// back to def when it is unset.
+func envFloat(name string, def float64) (float64, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return f, nil
+}
+
+// envBool parses the named variable as a boolean, falling back to def when
+// it is unset.
+func envBool(name string, def bool) (bool, error) {
+	v := os.Getenv(name)
+	if v == "" {
+		return def, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s: %v", name, err)
+	}
+
+	return b, nil
+}
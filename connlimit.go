@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"sync"
+)
+
+var (
+	errTooManyConnections       = errors.New("too many subscription connections, try again later")
+	errTooManyClientConnections = errors.New("too many subscription connections from your address")
+)
+
+// connLimiter counts the open subscription connections so that they stay
+// at or below max in total and perClient for each client IP. A zero limit
+// leaves that count unbounded, and a nil *connLimiter allows any number.
+type connLimiter struct {
+	max       int
+	perClient int
+	// trustProxy takes the client IP from X-Forwarded-For.
+	trustProxy bool
+
+	mu       sync.Mutex
+	open     int
+	byClient map[string]int
+}
+
+func newConnLimiter(max, perClient int, trustProxy bool) *connLimiter {
+	return &connLimiter{
+		max:        max,
+		perClient:  perClient,
+		trustProxy: trustProxy,
+		byClient:   make(map[string]int),
+	}
+}
+
+// acquire takes a slot for a new connection of client, saying which limit
+// is reached when there is none left. Every successful acquire needs a
+// release.
+func (l *connLimiter) acquire(client string) error {
+	if l == nil {
+		return nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.max > 0 && l.open >= l.max {
+		return errTooManyConnections
+	}
+	if l.perClient > 0 && l.byClient[client] >= l.perClient {
+		return errTooManyClientConnections
+	}
+	l.open++
+	l.byClient[client]++
+	return nil
+}
+
+// release frees the slot of a closed connection of client.
+func (l *connLimiter) release(client string) {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.open--
+	if l.byClient[client]--; l.byClient[client] <= 0 {
+		delete(l.byClient, client)
+	}
+}
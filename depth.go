@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// withDepthLimit rejects queries whose selections nest deeper than
+// maxDepth before they reach the executor. A maxDepth of 0 disables it.
+func withDepthLimit(next http.Handler, maxDepth int) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if maxDepth <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		req, err := peekRequest(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if depth := queryDepth(req.Query); depth > maxDepth {
+			writeGraphQLError(w, http.StatusBadRequest,
+				fmt.Sprintf("query depth %d exceeds the maximum of %d", depth, maxDepth))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// queryDepth returns how deeply the fields of query nest, counting
+// through fragments; `{ todo { Id } }` has a depth of 2. Queries that do
+// not parse report 0 and are left to the executor to reject.
+func queryDepth(query string) int {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return 0
+	}
+
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, def := range doc.Definitions {
+		if frag, ok := def.(*ast.FragmentDefinition); ok && frag.Name != nil {
+			fragments[frag.Name.Value] = frag
+		}
+	}
+
+	deepest := 0
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok {
+			if d := selectionDepth(op.SelectionSet, fragments, map[string]bool{}); d > deepest {
+				deepest = d
+			}
+		}
+	}
+
+	return deepest
+}
+
+// selectionDepth walks set, inlining fragment spreads. visiting holds
+// the fragments on the current path so a fragment cycle cannot loop.
+func selectionDepth(set *ast.SelectionSet, fragments map[string]*ast.FragmentDefinition, visiting map[string]bool) int {
+	if set == nil {
+		return 0
+	}
+
+	deepest := 0
+	for _, sel := range set.Selections {
+		d := 0
+		switch sel := sel.(type) {
+		case *ast.Field:
+			d = 1 + selectionDepth(sel.SelectionSet, fragments, visiting)
+		case *ast.InlineFragment:
+			d = selectionDepth(sel.SelectionSet, fragments, visiting)
+		case *ast.FragmentSpread:
+			if sel.Name == nil || visiting[sel.Name.Value] {
+				continue
+			}
+			frag, ok := fragments[sel.Name.Value]
+			if !ok {
+				continue
+			}
+			visiting[sel.Name.Value] = true
+			d = selectionDepth(frag.SelectionSet, fragments, visiting)
+			delete(visiting, sel.Name.Value)
+		}
+		if d > deepest {
+			deepest = d
+		}
+	}
+
+	return deepest
+}
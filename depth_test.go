@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestQueryDepth(t *testing.T) {
+	for _, tc := range []struct {
+		query string
+		depth int
+	}{
+		{`{ todoList { Id } }`, 2},
+		{`{ todoList { Id Tags { Name } } }`, 3},
+		{`{ todosConnection { edges { node { Id } } } }`, 4},
+		{`{ todoList { ...fields } } fragment fields on Todo { Id Tags { Name } }`, 3},
+		{`{ todoList { ... on Todo { Tags { Name } } } }`, 3},
+		{`{ todoList { ...a } } fragment a on Todo { ...a }`, 1},
+		{`not a query`, 0},
+	} {
+		if got := queryDepth(tc.query); got != tc.depth {
+			t.Errorf("queryDepth(%s) = %d, want %d", tc.query, got, tc.depth)
+		}
+	}
+}
+
+func TestDepthLimit(t *testing.T) {
+	h := withDepthLimit(serveGraphQL(newSchema(t, store.NewMemoryStore()), time.Second), 3)
+
+	w := postQuery(h, `{ todoList { Id Tags { Name } } }`)
+	if w.Code != http.StatusOK {
+		t.Errorf("query at the limit: status %d, body %s", w.Code, w.Body)
+	}
+	if res := decodeResponse(t, w); len(res.Errors) > 0 {
+		t.Errorf("query at the limit: errors %+v", res.Errors)
+	}
+
+	w = postQuery(h, `{ todosConnection { edges { node { Id } } } }`)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("query over the limit: status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	res := decodeResponse(t, w)
+	if len(res.Errors) != 1 || !strings.Contains(res.Errors[0].Message, "depth 4 exceeds the maximum of 3") {
+		t.Errorf("query over the limit: errors %+v", res.Errors)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build mysql
+// +build mysql
+
+package main
+
+import _ "github.com/go-sql-driver/mysql"
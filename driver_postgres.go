@@ -0,0 +1,6 @@
+//go:build postgres
+// +build postgres
+
+package main
+
+import _ "github.com/lib/pq"
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// csvHeader names the columns written by exportCSV.
+var csvHeader = []string{"Id", "Text", "Done", "Status", "Created", "DueDate", "Estimate", "Priority", "Position"}
+
+// serveExport streams every todo of the user as a backup:
+//
+//	GET /export?format=csv   CSV with a header row
+//	GET /export?format=json  a JSON array, the default
+//
+// Rows are written as they are read from the store. Once the first one is
+// out the status is sent, so a failure midway only shows as a cut off
+// body.
+func serveExport(s store.TodoStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			return
+		}
+
+		var err error
+		switch format := r.URL.Query().Get("format"); format {
+		case "csv":
+			w.Header().Set("Content-Type", "text/csv")
+			w.Header().Set("Content-Disposition", `attachment; filename="todos.csv"`)
+			err = exportCSV(w, r, s)
+		case "", "json":
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Content-Disposition", `attachment; filename="todos.json"`)
+			err = exportJSON(w, r, s)
+		default:
+			writeJSONError(w, http.StatusBadRequest, "unknown format "+strconv.Quote(format)+", use csv or json")
+		}
+		if err != nil {
+			log.Printf("ERROR: export: %v", err)
+		}
+	}
+}
+
+func exportCSV(w http.ResponseWriter, r *http.Request, s store.TodoStore) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	err := s.Each(r.Context(), func(todo model.Todo) error {
+		return cw.Write([]string{
+			strconv.Itoa(todo.Id),
+			todo.Text,
+			strconv.FormatBool(todo.Done),
+			string(todo.Status),
+			exportTime(todo.Created),
+			exportTime(todo.DueDate),
+			strconv.Itoa(todo.Estimate),
+			todo.Priority.String(),
+			strconv.FormatFloat(todo.Position, 'f', -1, 64),
+		})
+	})
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+
+	return cw.Error()
+}
+
+func exportJSON(w http.ResponseWriter, r *http.Request, s store.TodoStore) error {
+	if _, err := w.Write([]byte("[")); err != nil {
+		return err
+	}
+
+	first := true
+	err := s.Each(r.Context(), func(todo model.Todo) error {
+		row, err := json.Marshal(todo)
+		if err != nil {
+			return err
+		}
+		if !first {
+			row = append([]byte(","), row...)
+		}
+		first = false
+
+		_, err = w.Write(row)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write([]byte("]\n"))
+	return err
+}
+
+// exportTime formats t as RFC 3339, leaving unset times empty.
+func exportTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// newExportStore returns a store with two todos, one with a text that
+// needs quoting in CSV.
+func newExportStore(t *testing.T) store.TodoStore {
+	t.Helper()
+
+	s := store.NewMemoryStore()
+	for _, todo := range []model.Todo{
+		{Text: "buy milk", Status: model.StatusTodo},
+		{Text: `say "hi", then leave`, Status: model.StatusDone, Done: true, Estimate: 3, Priority: model.PriorityHigh},
+	} {
+		todo := todo
+		if err := s.Create(context.Background(), &todo); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+func TestExportCSV(t *testing.T) {
+	w := serve(serveExport(newExportStore(t)), httptest.NewRequest(http.MethodGet, "/export?format=csv", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("status %d, Content-Type %q", w.Code, w.Header().Get("Content-Type"))
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("export is not CSV: %v\n%s", err, w.Body)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("got %d rows, want a header and 2 todos: %v", len(rows), rows)
+	}
+	if strings.Join(rows[0], ",") != strings.Join(csvHeader, ",") {
+		t.Errorf("header = %v, want %v", rows[0], csvHeader)
+	}
+
+	column := map[string]int{}
+	for i, name := range csvHeader {
+		column[name] = i
+	}
+	second := rows[2]
+	for name, want := range map[string]string{
+		"Id":       "2",
+		"Text":     `say "hi", then leave`,
+		"Done":     "true",
+		"Status":   "DONE",
+		"DueDate":  "",
+		"Estimate": "3",
+		"Priority": model.PriorityHigh.String(),
+	} {
+		if got := second[column[name]]; got != want {
+			t.Errorf("%s of the second todo = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestExportJSON(t *testing.T) {
+	for _, path := range []string{"/export", "/export?format=json"} {
+		w := serve(serveExport(newExportStore(t)), httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d", path, w.Code)
+		}
+
+		var todos []model.Todo
+		if err := json.Unmarshal(w.Body.Bytes(), &todos); err != nil {
+			t.Fatalf("%s: export is not JSON: %v\n%s", path, err, w.Body)
+		}
+		if len(todos) != 2 || todos[0].Text != "buy milk" || todos[1].Text != `say "hi", then leave` {
+			t.Errorf("%s: exported %+v", path, todos)
+		}
+	}
+
+	w := serve(serveExport(store.NewMemoryStore()), httptest.NewRequest(http.MethodGet, "/export", nil))
+	var todos []model.Todo
+	if err := json.Unmarshal(w.Body.Bytes(), &todos); err != nil || len(todos) != 0 {
+		t.Errorf("empty export = %s, want []", w.Body)
+	}
+}
+
+func TestExportUnknownFormat(t *testing.T) {
+	w := serve(serveExport(newExportStore(t)), httptest.NewRequest(http.MethodGet, "/export?format=xml", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
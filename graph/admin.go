@@ -0,0 +1,22 @@
+package graph
+
+import (
+	"context"
+)
+
+// errAdminRequired is returned by admin-only fields for other callers.
+var errAdminRequired = &codedError{code: CodeForbidden, message: "admin access required"}
+
+type adminKey struct{}
+
+// WithAdmin marks ctx as belonging to an administrator, unlocking the
+// admin-only fields of the schema.
+func WithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, adminKey{}, true)
+}
+
+// IsAdmin reports whether ctx was marked by WithAdmin.
+func IsAdmin(ctx context.Context) bool {
+	admin, _ := ctx.Value(adminKey{}).(bool)
+	return admin
+}
@@ -0,0 +1,70 @@
+package graph_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph/graphtest"
+)
+
+// doAdmin runs query against env the way Env.Do does, but as an
+// administrator.
+func doAdmin(env *graphtest.Env, query string) *graphql.Result {
+	return graphql.Do(graphql.Params{
+		Schema:        env.Schema,
+		RequestString: query,
+		Context:       graph.WithAdmin(graph.WithLoader(context.Background())),
+	})
+}
+
+func TestIntegrityCheck(t *testing.T) {
+	env := newEnv(t)
+
+	res := doAdmin(env, `mutation { integrityCheck }`)
+	if len(res.Errors) > 0 {
+		t.Fatal(res.Errors)
+	}
+	var data struct{ IntegrityCheck []string }
+	decode(t, res, &data)
+	if len(data.IntegrityCheck) != 1 || data.IntegrityCheck[0] != "ok" {
+		t.Errorf("integrityCheck = %q, want [ok]", data.IntegrityCheck)
+	}
+
+	res = env.Do(`mutation { integrityCheck }`, nil)
+	if code := errorCode(t, res); code != graph.CodeForbidden {
+		t.Errorf("integrityCheck without admin: code = %q, want %q", code, graph.CodeForbidden)
+	}
+}
+
+func TestConfig(t *testing.T) {
+	settings := []graph.Setting{
+		{Name: "REQUEST_TIMEOUT", Value: "5s"},
+		{Name: "API_KEY", Value: "set"},
+	}
+	env := newEnv(t, graph.WithSettings(settings))
+
+	res := doAdmin(env, `{ config { name value } }`)
+	if len(res.Errors) > 0 {
+		t.Fatal(res.Errors)
+	}
+	var data struct {
+		Config []struct{ Name, Value string }
+	}
+	decode(t, res, &data)
+	if len(data.Config) != len(settings) {
+		t.Fatalf("config = %+v, want %+v", data.Config, settings)
+	}
+	for i, setting := range settings {
+		if data.Config[i].Name != setting.Name || data.Config[i].Value != setting.Value {
+			t.Errorf("config[%d] = %+v, want %+v", i, data.Config[i], setting)
+		}
+	}
+
+	res = env.Do(`{ config { name value } }`, nil)
+	if code := errorCode(t, res); code != graph.CodeForbidden {
+		t.Errorf("config without admin: code = %q, want %q", code, graph.CodeForbidden)
+	}
+}
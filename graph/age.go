@@ -0,0 +1,43 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+const day = 24 * time.Hour
+
+// ageDistribution is the source value for AgeDistribution.
+type ageDistribution struct {
+	LessThanOneDay     int
+	OneToSevenDays     int
+	SevenToThirtyDays  int
+	MoreThanThirtyDays int
+}
+
+// newAgeDistribution buckets the todos that are not done by how long ago,
+// relative to now, they were created. Age is an elapsed duration, so the
+// buckets do not depend on time zones. Todos without a Created time are
+// skipped.
+func newAgeDistribution(todos []model.Todo, now time.Time) *ageDistribution {
+	d := &ageDistribution{}
+	for _, todo := range todos {
+		if todo.Status == model.StatusDone || todo.Created.IsZero() {
+			continue
+		}
+
+		switch age := now.Sub(todo.Created); {
+		case age < day:
+			d.LessThanOneDay++
+		case age < 7*day:
+			d.OneToSevenDays++
+		case age < 30*day:
+			d.SevenToThirtyDays++
+		default:
+			d.MoreThanThirtyDays++
+		}
+	}
+
+	return d
+}
@@ -0,0 +1,38 @@
+package graph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+func TestNewAgeDistribution(t *testing.T) {
+	now := time.Date(2020, 3, 31, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+
+	todos := []model.Todo{
+		{Status: model.StatusTodo, Created: ago(time.Hour)},
+		{Status: model.StatusInProgress, Created: ago(day - time.Second)},
+		{Status: model.StatusTodo, Created: ago(day)},
+		{Status: model.StatusTodo, Created: ago(7*day - time.Second)},
+		{Status: model.StatusTodo, Created: ago(7 * day)},
+		{Status: model.StatusTodo, Created: ago(30*day - time.Second)},
+		{Status: model.StatusTodo, Created: ago(30 * day)},
+		{Status: model.StatusTodo, Created: ago(400 * day)},
+		// neither done todos nor ones without a Created time count
+		{Status: model.StatusDone, Created: ago(time.Hour)},
+		{Status: model.StatusTodo},
+	}
+
+	got := *newAgeDistribution(todos, now)
+	want := ageDistribution{
+		LessThanOneDay:     2,
+		OneToSevenDays:     2,
+		SevenToThirtyDays:  2,
+		MoreThanThirtyDays: 2,
+	}
+	if got != want {
+		t.Errorf("newAgeDistribution = %+v, want %+v", got, want)
+	}
+}
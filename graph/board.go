@@ -0,0 +1,31 @@
+package graph
+
+import "github.com/nevzatalkan/golang-graphql-todo-example/model"
+
+// board is the source value for boardType.
+type board struct {
+	Todo       []model.Todo
+	InProgress []model.Todo
+	Done       []model.Todo
+}
+
+// newBoard sorts todos into their columns, keeping their relative order.
+func newBoard(todos []model.Todo) *board {
+	b := &board{
+		Todo:       []model.Todo{},
+		InProgress: []model.Todo{},
+		Done:       []model.Todo{},
+	}
+	for _, todo := range todos {
+		switch todo.Status {
+		case model.StatusDone:
+			b.Done = append(b.Done, todo)
+		case model.StatusInProgress:
+			b.InProgress = append(b.InProgress, todo)
+		default:
+			b.Todo = append(b.Todo, todo)
+		}
+	}
+
+	return b
+}
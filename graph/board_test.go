@@ -0,0 +1,50 @@
+package graph_test
+
+import "testing"
+
+func TestBoard(t *testing.T) {
+	env := newEnv(t)
+	do(t, env, `mutation { createTodo(Text: "plan the trip", Status: IN_PROGRESS) { Id } }`, nil, &struct{}{})
+
+	var data struct {
+		Board struct {
+			Todo, InProgress, Done []todoJSON
+		}
+	}
+	do(t, env, `{ board { todo { Id } inProgress { Id } done { Id } } }`, nil, &data)
+
+	for _, column := range []struct {
+		name string
+		got  []todoJSON
+		want []int
+	}{
+		{"todo", data.Board.Todo, []int{1}},
+		{"inProgress", data.Board.InProgress, []int{2, 4}},
+		{"done", data.Board.Done, []int{3}},
+	} {
+		if got := todoIds(column.got); !equalInts(got, column.want) {
+			t.Errorf("%s column = %v, want %v", column.name, got, column.want)
+		}
+	}
+}
+
+// todoIds returns the Ids of todos in order.
+func todoIds(todos []todoJSON) []int {
+	ids := []int{}
+	for _, todo := range todos {
+		ids = append(ids, todo.Id)
+	}
+	return ids
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// todoConnection is the source value for the Relay-style TodoConnection.
+type todoConnection struct {
+	Edges    []todoEdge
+	PageInfo pageInfo
+}
+
+type todoEdge struct {
+	Cursor string
+	Node   model.Todo
+}
+
+type pageInfo struct {
+	HasNextPage bool
+	EndCursor   *string
+}
+
+// cursor is the JSON payload behind an opaque connection cursor. It
+// carries the sort field and its value alongside the Id so pages stay
+// stable under any ordering.
+type cursor struct {
+	Field store.SortField `json:"f"`
+	Value interface{}     `json:"v,omitempty"`
+	Id    int             `json:"id"`
+}
+
+// encodeCursor returns the opaque cursor pointing just past todo in order.
+func encodeCursor(todo model.Todo, order store.Sort) string {
+	c := cursor{Field: order.Field, Id: todo.Id}
+	if order.Field != store.SortByID {
+		c.Value = order.ValueOf(todo)
+	}
+
+	raw, _ := json.Marshal(c)
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+// decodeCursor returns the keyset encoded by encodeCursor, checking the
+// cursor was made for the same sort field.
+func decodeCursor(s string, order store.Sort) (*store.Keyset, error) {
+	invalid := validationError("invalid cursor %q", s)
+
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, invalid
+	}
+
+	var c cursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, invalid
+	}
+	if c.Field != order.Field {
+		return nil, validationError("cursor was issued for sortBy %s, not %s", c.Field, order.Field)
+	}
+
+	key := &store.Keyset{Id: c.Id}
+	switch order.Field {
+	case store.SortByText:
+		text, ok := c.Value.(string)
+		if !ok {
+			return nil, invalid
+		}
+		key.Value = text
+	case store.SortByCreated:
+		stamp, _ := c.Value.(string)
+		created, err := time.Parse(time.RFC3339Nano, stamp)
+		if err != nil {
+			return nil, invalid
+		}
+		key.Value = created
+	case store.SortByPriority:
+		// JSON numbers decode as float64
+		priority, ok := c.Value.(float64)
+		if !ok {
+			return nil, invalid
+		}
+		key.Value = model.Priority(priority)
+	case store.SortByPosition:
+		position, ok := c.Value.(float64)
+		if !ok {
+			return nil, invalid
+		}
+		key.Value = position
+	}
+
+	return key, nil
+}
+
+// newTodoConnection builds a page from rows fetched with a limit of
+// first+1; the extra row only tells whether another page exists.
+func newTodoConnection(rows []model.Todo, first int, order store.Sort) *todoConnection {
+	conn := &todoConnection{Edges: []todoEdge{}}
+	if len(rows) > first {
+		conn.PageInfo.HasNextPage = true
+		rows = rows[:first]
+	}
+
+	for _, todo := range rows {
+		conn.Edges = append(conn.Edges, todoEdge{Cursor: encodeCursor(todo, order), Node: todo})
+	}
+	if n := len(conn.Edges); n > 0 {
+		conn.PageInfo.EndCursor = &conn.Edges[n-1].Cursor
+	}
+
+	return conn
+}
@@ -0,0 +1,133 @@
+package graph_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph/graphtest"
+)
+
+// walkConnection pages through todosConnection with pages of first todos
+// and the given sortBy and sortOrder, returning the Ids in the order seen.
+func walkConnection(t *testing.T, env *graphtest.Env, first int, sortBy, sortOrder string) []int {
+	t.Helper()
+
+	query := fmt.Sprintf(`query($after: String) {
+		todosConnection(first: %d, after: $after, sortBy: %s, sortOrder: %s) {
+			edges { cursor node { Id } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`, first, sortBy, sortOrder)
+
+	ids := []int{}
+	variables := map[string]interface{}{}
+	for page := 0; ; page++ {
+		if page > 100 {
+			t.Fatal("todosConnection never ran out of pages")
+		}
+
+		var data struct {
+			TodosConnection struct {
+				Edges []struct {
+					Cursor string
+					Node   todoJSON
+				}
+				PageInfo struct {
+					HasNextPage bool
+					EndCursor   *string
+				}
+			}
+		}
+		do(t, env, query, variables, &data)
+
+		conn := data.TodosConnection
+		if len(conn.Edges) > first {
+			t.Fatalf("page %d has %d edges, more than first: %d", page, len(conn.Edges), first)
+		}
+		for _, edge := range conn.Edges {
+			ids = append(ids, edge.Node.Id)
+		}
+		if !conn.PageInfo.HasNextPage {
+			return ids
+		}
+		if conn.PageInfo.EndCursor == nil {
+			t.Fatalf("page %d has a next page but no endCursor", page)
+		}
+		variables["after"] = *conn.PageInfo.EndCursor
+	}
+}
+
+func TestTodosConnectionWalk(t *testing.T) {
+	env := newEnv(t)
+	for i := 0; i < 7; i++ {
+		do(t, env, `mutation($text: String!) { createTodo(Text: $text) { Id } }`,
+			map[string]interface{}{"text": fmt.Sprintf("todo %d", i)}, &struct{}{})
+	}
+
+	// 10 todos in pages of 3, the last one short
+	got := walkConnection(t, env, 3, "ID", "ASC")
+	want := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	if !equalInts(got, want) {
+		t.Errorf("walking by Id saw %v, want %v", got, want)
+	}
+
+	got = walkConnection(t, env, 5, "ID", "DESC")
+	want = []int{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	if !equalInts(got, want) {
+		t.Errorf("walking by Id descending saw %v, want %v", got, want)
+	}
+}
+
+func TestTodosConnectionInvalidCursor(t *testing.T) {
+	env := newEnv(t)
+
+	res := env.Do(`{ todosConnection(after: "not a cursor") { edges { cursor } } }`, nil)
+	if len(res.Errors) == 0 {
+		t.Error("an invalid cursor was accepted")
+	}
+}
+
+func TestTodosConnectionSortedWalk(t *testing.T) {
+	env := newEnv(t)
+	for _, todo := range []struct{ text, priority string }{
+		{"book the flights", "HIGH"},
+		{"apply for the visa", "LOW"},
+		{"write the report", "HIGH"},
+		{"call the bank", "MEDIUM"},
+		{"apply for the visa", "HIGH"},
+	} {
+		do(t, env, `mutation($text: String!, $priority: Priority) { createTodo(Text: $text, Priority: $priority) { Id } }`,
+			map[string]interface{}{"text": todo.text, "priority": todo.priority}, &struct{}{})
+	}
+
+	for _, sortBy := range []string{"TEXT", "CREATED_AT", "PRIORITY", "POSITION"} {
+		for _, sortOrder := range []string{"ASC", "DESC"} {
+			var list struct{ TodoList []todoJSON }
+			do(t, env, fmt.Sprintf(`{ todoList(sortBy: %s, sortOrder: %s) { Id } }`, sortBy, sortOrder), nil, &list)
+			want := todoIds(list.TodoList)
+
+			// pages of 2 split several runs of equal values
+			if got := walkConnection(t, env, 2, sortBy, sortOrder); !equalInts(got, want) {
+				t.Errorf("walking by %s %s saw %v, want %v", sortBy, sortOrder, got, want)
+			}
+		}
+	}
+}
+
+func TestTodosConnectionCursorOfOtherSort(t *testing.T) {
+	env := newEnv(t)
+
+	var data struct {
+		TodosConnection struct {
+			PageInfo struct{ EndCursor string }
+		}
+	}
+	do(t, env, `{ todosConnection(first: 1, sortBy: TEXT) { pageInfo { endCursor } } }`, nil, &data)
+
+	res := env.Do(`query($after: String) { todosConnection(first: 1, after: $after, sortBy: PRIORITY) { edges { cursor } } }`,
+		map[string]interface{}{"after": data.TodosConnection.PageInfo.EndCursor})
+	if code := errorCode(t, res); code != graph.CodeValidation {
+		t.Errorf("code = %q, want %q", code, graph.CodeValidation)
+	}
+}
@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// criticalPath is the source value for CriticalPath.
+type criticalPath struct {
+	Todos         []model.Todo
+	TotalEstimate int
+}
+
+// longer reports whether p is a worse bottleneck than q: a bigger total
+// estimate, or as much estimate spread over more todos.
+func (p *criticalPath) longer(q *criticalPath) bool {
+	if p.TotalEstimate != q.TotalEstimate {
+		return p.TotalEstimate > q.TotalEstimate
+	}
+	return len(p.Todos) > len(q.Todos)
+}
+
+// newCriticalPath finds the chain of not done todos, each depending on the
+// next, with the biggest total estimate. The chain is returned in the
+// order the todos have to be done, so it starts with the one nothing else
+// waits on. Ties go to the todo listed first.
+func newCriticalPath(todos []model.Todo, deps []model.Dependency) (*criticalPath, error) {
+	open := map[int]model.Todo{}
+	for _, todo := range todos {
+		if todo.Status != model.StatusDone {
+			open[todo.Id] = todo
+		}
+	}
+	g := model.NewDependencyGraph(deps)
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[int]int{}
+	// chains[id] is the heaviest chain starting at id, listed from id
+	// down to the last dependency
+	chains := map[int]*criticalPath{}
+
+	var walk func(id int) error
+	walk = func(id int) error {
+		switch state[id] {
+		case visiting:
+			return conflictError("dependency cycle through todo %d", id)
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+
+		best := &criticalPath{}
+		for _, next := range g[id] {
+			if _, ok := open[next]; !ok {
+				continue
+			}
+			if err := walk(next); err != nil {
+				return err
+			}
+			if chains[next].longer(best) {
+				best = chains[next]
+			}
+		}
+
+		todo := open[id]
+		chains[id] = &criticalPath{
+			Todos:         append([]model.Todo{todo}, best.Todos...),
+			TotalEstimate: todo.Estimate + best.TotalEstimate,
+		}
+		state[id] = visited
+		return nil
+	}
+
+	path := &criticalPath{Todos: []model.Todo{}}
+	for _, todo := range todos {
+		if _, ok := open[todo.Id]; !ok {
+			continue
+		}
+		if err := walk(todo.Id); err != nil {
+			return nil, err
+		}
+		if chains[todo.Id].longer(path) {
+			path = chains[todo.Id]
+		}
+	}
+
+	// dependencies come first in the order of work
+	for i, j := 0, len(path.Todos)-1; i < j; i, j = i+1, j-1 {
+		path.Todos[i], path.Todos[j] = path.Todos[j], path.Todos[i]
+	}
+
+	return path, nil
+}
@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+func TestNewCriticalPath(t *testing.T) {
+	todos := []model.Todo{
+		{Id: 1, Status: model.StatusTodo, Estimate: 3},
+		{Id: 2, Status: model.StatusInProgress, Estimate: 5},
+		{Id: 3, Status: model.StatusTodo, Estimate: 2},
+		{Id: 4, Status: model.StatusDone, Estimate: 8},
+		{Id: 5, Status: model.StatusTodo, Estimate: 1},
+		{Id: 6, Status: model.StatusTodo, Estimate: 6},
+	}
+	deps := []model.Dependency{
+		{TodoId: 1, DependsOn: 2},
+		{TodoId: 2, DependsOn: 3},
+		{TodoId: 1, DependsOn: 5},
+		// done todos are off the path however big their estimate
+		{TodoId: 5, DependsOn: 4},
+	}
+
+	path, err := newCriticalPath(todos, deps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := []int{}
+	for _, todo := range path.Todos {
+		ids = append(ids, todo.Id)
+	}
+	if want := []int{3, 2, 1}; len(ids) != len(want) || ids[0] != 3 || ids[1] != 2 || ids[2] != 1 {
+		t.Errorf("path = %v, want %v", ids, want)
+	}
+	if path.TotalEstimate != 10 {
+		t.Errorf("TotalEstimate = %d, want 10", path.TotalEstimate)
+	}
+}
+
+func TestNewCriticalPathEmpty(t *testing.T) {
+	path, err := newCriticalPath(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(path.Todos) != 0 || path.TotalEstimate != 0 {
+		t.Errorf("path of no todos = %+v", path)
+	}
+}
+
+func TestNewCriticalPathCycle(t *testing.T) {
+	todos := []model.Todo{
+		{Id: 1, Status: model.StatusTodo},
+		{Id: 2, Status: model.StatusTodo},
+	}
+	deps := []model.Dependency{{TodoId: 1, DependsOn: 2}, {TodoId: 2, DependsOn: 1}}
+
+	if _, err := newCriticalPath(todos, deps); err == nil {
+		t.Error("a dependency cycle was not reported")
+	}
+}
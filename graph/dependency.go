@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// addDependency makes todoID depend on dependsOn, refusing dependencies
+// that would close a cycle.
+func addDependency(ctx context.Context, tx store.TodoStore, todoID, dependsOn int) (*model.Todo, error) {
+	todo, err := tx.Get(ctx, todoID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Get(ctx, dependsOn); err != nil {
+		return nil, err
+	}
+
+	deps, err := tx.Dependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	g := model.NewDependencyGraph(deps)
+	if g.Reaches(dependsOn, todoID) {
+		return nil, conflictError("todo %d cannot depend on %d: that would create a cycle", todoID, dependsOn)
+	}
+	for _, id := range g[todoID] {
+		if id == dependsOn {
+			return todo, nil
+		}
+	}
+
+	err = tx.AddDependency(ctx, &model.Dependency{TodoId: todoID, DependsOn: dependsOn})
+	return todo, err
+}
+
+// relatedTodos resolves the todos on the other end of todo's
+// dependencies: the ones it depends on, or with dependents set, the ones
+// depending on it. They come in the order the dependencies were added.
+func relatedTodos(ctx context.Context, s store.TodoStore, todo *model.Todo, dependents bool) ([]model.Todo, error) {
+	l := loaderFrom(ctx)
+	deps, err := l.dependencies(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	todos := []model.Todo{}
+	for _, dep := range deps {
+		from, to := dep.TodoId, dep.DependsOn
+		if dependents {
+			from, to = to, from
+		}
+		if from != todo.Id {
+			continue
+		}
+		if related, ok := l.todo(to); ok {
+			todos = append(todos, related)
+		}
+	}
+
+	return todos, nil
+}
+
+// blockedTodos keeps the todos that are not done yet but depend on a todo
+// that is not done either, preserving their order.
+func blockedTodos(todos []model.Todo, deps []model.Dependency) []model.Todo {
+	status := make(map[int]model.Status, len(todos))
+	for _, todo := range todos {
+		status[todo.Id] = todo.Status
+	}
+
+	blocked := map[int]bool{}
+	for _, dep := range deps {
+		if s, ok := status[dep.DependsOn]; ok && s != model.StatusDone {
+			blocked[dep.TodoId] = true
+		}
+	}
+
+	result := []model.Todo{}
+	for _, todo := range todos {
+		if blocked[todo.Id] && todo.Status != model.StatusDone {
+			result = append(result, todo)
+		}
+	}
+
+	return result
+}
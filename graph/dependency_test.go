@@ -0,0 +1,83 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+)
+
+func TestAddDependency(t *testing.T) {
+	env := newEnv(t)
+
+	var added struct {
+		AddDependency struct {
+			Id        int
+			BlockedBy []todoJSON
+		}
+	}
+	do(t, env, `mutation { addDependency(Id: 1, dependsOn: 2) { Id blockedBy { Id } } }`, nil, &added)
+	if got := todoIds(added.AddDependency.BlockedBy); !equalInts(got, []int{2}) {
+		t.Errorf("blockedBy = %v, want [2]", got)
+	}
+
+	var blocks struct{ Todo struct{ Blocks []todoJSON } }
+	do(t, env, `{ todo(Id: 2) { blocks { Id } } }`, nil, &blocks)
+	if got := todoIds(blocks.Todo.Blocks); !equalInts(got, []int{1}) {
+		t.Errorf("todo 2 blocks %v, want [1]", got)
+	}
+
+	// adding the same dependency again changes nothing
+	do(t, env, `mutation { addDependency(Id: 1, dependsOn: 2) { Id } }`, nil, &struct{}{})
+	var again struct {
+		Todo struct{ BlockedBy []todoJSON }
+	}
+	do(t, env, `{ todo(Id: 1) { blockedBy { Id } } }`, nil, &again)
+	if got := todoIds(again.Todo.BlockedBy); !equalInts(got, []int{2}) {
+		t.Errorf("blockedBy after adding twice = %v, want [2]", got)
+	}
+
+	res := env.Do(`mutation { addDependency(Id: 1, dependsOn: 404) { Id } }`, nil)
+	if code := errorCode(t, res); code != graph.CodeNotFound {
+		t.Errorf("depending on a missing todo: code = %q, want %q", code, graph.CodeNotFound)
+	}
+}
+
+func TestBlockedTodos(t *testing.T) {
+	env := newEnv(t)
+	// 1 waits for 2, which is in progress; 2 waits for 3, which is done
+	do(t, env, `mutation {
+		a: addDependency(Id: 1, dependsOn: 2) { Id }
+		b: addDependency(Id: 2, dependsOn: 3) { Id }
+	}`, nil, &struct{}{})
+
+	var data struct{ BlockedTodos []todoJSON }
+	do(t, env, `{ blockedTodos { Id } }`, nil, &data)
+	if got := todoIds(data.BlockedTodos); !equalInts(got, []int{1}) {
+		t.Errorf("blockedTodos = %v, want [1]", got)
+	}
+
+	do(t, env, `mutation { updateTodo(Id: 2, Status: DONE) { affectedRows } }`, nil, &struct{}{})
+	do(t, env, `{ blockedTodos { Id } }`, nil, &data)
+	if len(data.BlockedTodos) != 0 {
+		t.Errorf("blockedTodos = %v once every dependency is done, want none", todoIds(data.BlockedTodos))
+	}
+}
+
+func TestAddDependencyRejectsCycles(t *testing.T) {
+	env := newEnv(t)
+	do(t, env, `mutation {
+		a: addDependency(Id: 1, dependsOn: 2) { Id }
+		b: addDependency(Id: 2, dependsOn: 3) { Id }
+	}`, nil, &struct{}{})
+
+	for _, query := range []string{
+		`mutation { addDependency(Id: 3, dependsOn: 1) { Id } }`,
+		`mutation { addDependency(Id: 2, dependsOn: 1) { Id } }`,
+		`mutation { addDependency(Id: 1, dependsOn: 1) { Id } }`,
+	} {
+		res := env.Do(query, nil)
+		if code := errorCode(t, res); code != graph.CodeConflict {
+			t.Errorf("%s: code = %q, want %q", query, code, graph.CodeConflict)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// dueBefore keeps the todos that have a due date before t, preserving
+// their order. Todos without a due date are dropped.
+func dueBefore(todos []model.Todo, t time.Time) []model.Todo {
+	due := []model.Todo{}
+	for _, todo := range todos {
+		if !todo.DueDate.IsZero() && todo.DueDate.Before(t) {
+			due = append(due, todo)
+		}
+	}
+
+	return due
+}
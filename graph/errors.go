@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// Codes put into the extensions of GraphQL errors so clients can tell
+// failures apart without parsing messages.
+const (
+	CodeNotFound           = "NOT_FOUND"
+	CodeValidation         = "VALIDATION"
+	CodeConflict           = "CONFLICT"
+	CodeForbidden          = "FORBIDDEN"
+	CodeInternal           = "INTERNAL"
+	CodeServiceUnavailable = "SERVICE_UNAVAILABLE"
+)
+
+// codedError is an error whose code is reported in the extensions of the
+// GraphQL error it becomes.
+type codedError struct {
+	code    string
+	message string
+}
+
+func (e *codedError) Error() string {
+	return e.message
+}
+
+func (e *codedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+// validationError reports arguments the client has to fix.
+func validationError(format string, args ...interface{}) error {
+	return &codedError{code: CodeValidation, message: fmt.Sprintf(format, args...)}
+}
+
+// conflictError reports a request that is valid on its own but clashes
+// with the current state of the data.
+func conflictError(format string, args ...interface{}) error {
+	return &codedError{code: CodeConflict, message: fmt.Sprintf(format, args...)}
+}
+
+// errDiskFull replaces the driver's message when the database disk is
+// full, which clients can do nothing about.
+var errDiskFull = &codedError{
+	code:    CodeServiceUnavailable,
+	message: "the server cannot store changes right now because its storage is full; try again later",
+}
+
+// mapStorageErrors wraps a mutation resolver so that writes failing on a
+// full disk are logged and reported as errDiskFull instead.
+func mapStorageErrors(resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		result, err := resolve(p)
+		if store.IsDiskFull(err) {
+			log.Printf("ERROR: database storage full, writes are failing: %v", err)
+			return nil, errDiskFull
+		}
+		return result, err
+	}
+}
+
+// withErrorCodes gives every error of resolve a code: errors that carry
+// one keep it, a missing todo or tag is NOT_FOUND, a repeated text CONFLICT and
+// anything else is INTERNAL.
+func withErrorCodes(resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		result, err := resolve(p)
+		switch err.(type) {
+		case nil, gqlerrors.ExtendedError:
+			return result, err
+		}
+
+		switch err {
+		case store.ErrNotFound, store.ErrTagNotFound:
+			return result, &codedError{code: CodeNotFound, message: err.Error()}
+		case store.ErrDuplicateText:
+			return result, &codedError{code: CodeConflict, message: err.Error()}
+		}
+		return result, &codedError{code: CodeInternal, message: err.Error()}
+	}
+}
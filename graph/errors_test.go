@@ -0,0 +1,121 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// errFull is what sqlite answers a write with when its disk is full.
+var errFull = errors.New("database or disk is full")
+
+// fullStore fails every write the way a database on a full disk does.
+type fullStore struct {
+	*store.MemoryStore
+}
+
+func (fullStore) Create(ctx context.Context, todo *model.Todo) error { return errFull }
+
+func (fullStore) Update(ctx context.Context, todo *model.Todo, cols ...string) error {
+	return errFull
+}
+
+// WithTx runs fn on the store itself, so that writes in transactions fail
+// too.
+func (s fullStore) WithTx(ctx context.Context, fn func(tx store.TodoStore) error) error {
+	return fn(s)
+}
+
+func TestDiskFull(t *testing.T) {
+	ctx := context.Background()
+	s := fullStore{store.NewMemoryStore()}
+	if err := s.MemoryStore.Create(ctx, &model.Todo{Text: "already stored", Status: model.StatusTodo}); err != nil {
+		t.Fatal(err)
+	}
+	schema, err := graph.NewSchema(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{
+		`mutation { createTodo(Text: "one too many") { Id } }`,
+		`mutation { updateTodo(Id: 1, Done: true) { affectedRows } }`,
+	} {
+		res := graphql.Do(graphql.Params{Schema: schema, RequestString: query, Context: graph.WithLoader(ctx)})
+		if code := errorCode(t, res); code != graph.CodeServiceUnavailable {
+			t.Errorf("%s: code = %q, want %q", query, code, graph.CodeServiceUnavailable)
+		}
+		if msg := res.Errors[0].Message; strings.Contains(msg, errFull.Error()) {
+			t.Errorf("%s: message %q leaks the driver error", query, msg)
+		}
+	}
+
+	// reads do not write, so they keep working
+	res := graphql.Do(graphql.Params{Schema: schema, RequestString: `{ todo(Id: 1) { Text } }`, Context: graph.WithLoader(ctx)})
+	if len(res.Errors) > 0 {
+		t.Errorf("todo on a full disk: %v", res.Errors)
+	}
+}
+
+// brokenStore fails every List with an error the schema knows nothing
+// about.
+type brokenStore struct {
+	*store.MemoryStore
+}
+
+func (brokenStore) List(ctx context.Context, sort store.Sort) ([]model.Todo, error) {
+	return nil, errors.New("connection reset by peer")
+}
+
+func TestErrorCodes(t *testing.T) {
+	env := newEnv(t)
+
+	for _, tc := range []struct {
+		category string
+		query    string
+		code     string
+	}{
+		{"missing todo", `mutation { deleteTodo(Id: 404) { Id } }`, graph.CodeNotFound},
+		{"bad argument", `mutation { addTag(todoId: 1, name: "") { Id } }`, graph.CodeValidation},
+		{"refused transition", `mutation { transitionStatus(Id: 1, to: DONE) { Id } }`, graph.CodeConflict},
+		{"admin only", `{ config { name } }`, graph.CodeForbidden},
+	} {
+		if code := errorCode(t, env.Do(tc.query, nil)); code != tc.code {
+			t.Errorf("%s: code = %q, want %q", tc.category, code, tc.code)
+		}
+	}
+
+	schema, err := graph.NewSchema(brokenStore{store.NewMemoryStore()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := graphql.Do(graphql.Params{Schema: schema, RequestString: `{ todoList { Id } }`, Context: graph.WithLoader(context.Background())})
+	if code := errorCode(t, res); code != graph.CodeInternal {
+		t.Errorf("unexpected store error: code = %q, want %q", code, graph.CodeInternal)
+	}
+}
+
+func TestUniqueTextConflict(t *testing.T) {
+	env := newEnv(t)
+	if err := env.Store.RequireUniqueText(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{
+		`mutation { createTodo(Text: "write the report") { Id } }`,
+		`mutation { updateTodo(Id: 2, Text: "write the report") { affectedRows } }`,
+	} {
+		if code := errorCode(t, env.Do(query, nil)); code != graph.CodeConflict {
+			t.Errorf("%s: code = %q, want %q", query, code, graph.CodeConflict)
+		}
+	}
+
+	do(t, env, `mutation { createTodo(Text: "write the minutes") { Id } }`, nil, &struct{}{})
+}
@@ -0,0 +1,82 @@
+// Package graphtest sets up the GraphQL schema over a fresh in-memory
+// sqlite database, for tests that want to run real queries and mutations
+// without touching ./test.db.
+package graphtest
+
+import (
+	"context"
+
+	"github.com/go-xorm/xorm"
+	"github.com/graphql-go/graphql"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// Todos are the todos of the anonymous user that every database made by
+// New starts with, getting Ids 1, 2 and 3.
+var Todos = []model.Todo{
+	{Text: "write the report", Status: model.StatusTodo},
+	{Text: "review the pull request", Status: model.StatusInProgress},
+	{Text: "book the flights", Status: model.StatusDone, Done: true},
+}
+
+// Env is a schema together with the store behind it.
+type Env struct {
+	Schema graphql.Schema
+	Store  *store.XormStore
+}
+
+// New creates an in-memory sqlite database with the tables synced and
+// Todos inserted, and builds the schema over it with opts. Close the Env
+// when done with it.
+func New(opts ...graph.Option) (*Env, error) {
+	engine, err := xorm.NewEngine("sqlite3", ":memory:")
+	if err != nil {
+		return nil, err
+	}
+	// every connection to :memory: opens a database of its own
+	engine.SetMaxOpenConns(1)
+
+	s, err := store.NewXormStore(engine)
+	if err != nil {
+		engine.Close()
+		return nil, err
+	}
+
+	ctx := context.Background()
+	for _, todo := range Todos {
+		todo := todo
+		if err := s.Create(ctx, &todo); err != nil {
+			s.Close()
+			return nil, err
+		}
+	}
+
+	schema, err := graph.NewSchema(s, opts...)
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	return &Env{Schema: schema, Store: s}, nil
+}
+
+// Do runs query with variables the way /graphql does, as the anonymous
+// user and with a loader of its own.
+func (e *Env) Do(query string, variables map[string]interface{}) *graphql.Result {
+	return graphql.Do(graphql.Params{
+		Schema:         e.Schema,
+		RequestString:  query,
+		VariableValues: variables,
+		Context:        graph.WithLoader(context.Background()),
+	})
+}
+
+// Close drops the database.
+func (e *Env) Close() error {
+	return e.Store.Close()
+}
@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// createOnce creates todo unless the user already created one with the same
+// ClientMutationId, in which case that todo is returned and nothing is
+// written.
+func createOnce(ctx context.Context, s store.TodoStore, todo *model.Todo) (*model.Todo, error) {
+	key := *todo.ClientMutationId
+
+	var existing *model.Todo
+	err := s.WithTx(ctx, func(tx store.TodoStore) error {
+		found, err := tx.GetByClientMutationId(ctx, key)
+		switch err {
+		case nil:
+			existing = found
+			return nil
+		case store.ErrNotFound:
+			existing = nil
+			return tx.Create(ctx, todo)
+		default:
+			return err
+		}
+	})
+	if err != nil {
+		// a concurrent retry may have won the race for the unique key
+		if found, lookupErr := s.GetByClientMutationId(ctx, key); lookupErr == nil {
+			return found, nil
+		}
+		return nil, err
+	}
+
+	return existing, nil
+}
@@ -0,0 +1,106 @@
+package graph_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph/graphtest"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+const createOnceQuery = `mutation ($key: String) { createTodo(Text: "pay the rent", clientMutationId: $key) { Id } }`
+
+// createWithKey runs createOnceQuery with key, as the anonymous user, and
+// returns the Id of the todo answered.
+func createWithKey(t *testing.T, env *graphtest.Env, key interface{}) int {
+	t.Helper()
+
+	res := env.Do(createOnceQuery, map[string]interface{}{"key": key})
+	if len(res.Errors) > 0 {
+		t.Fatal(res.Errors)
+	}
+	var data struct{ CreateTodo todoJSON }
+	decode(t, res, &data)
+	return data.CreateTodo.Id
+}
+
+func TestCreateTodoIdempotent(t *testing.T) {
+	env := newEnv(t)
+
+	first := createWithKey(t, env, "rent-june")
+	if again := createWithKey(t, env, "rent-june"); again != first {
+		t.Errorf("retry with the same key created todo %d, want %d", again, first)
+	}
+	if other := createWithKey(t, env, "rent-july"); other == first {
+		t.Errorf("another key returned todo %d again", first)
+	}
+	if n := countTodos(t, env); n != 5 {
+		t.Errorf("%d todos, want the 3 seeded and one per key", n)
+	}
+
+	// without a key every call creates a todo
+	if createWithKey(t, env, nil) == createWithKey(t, env, nil) {
+		t.Error("two creates without a key returned the same todo")
+	}
+	if n := countTodos(t, env); n != 7 {
+		t.Errorf("%d todos after two creates without a key, want 7", n)
+	}
+}
+
+func TestCreateTodoIdempotentPerUser(t *testing.T) {
+	env := newEnv(t)
+	first := createWithKey(t, env, "rent-june")
+
+	res := graphql.Do(graphql.Params{
+		Schema:         env.Schema,
+		RequestString:  createOnceQuery,
+		VariableValues: map[string]interface{}{"key": "rent-june"},
+		Context:        graph.WithLoader(store.WithUser(context.Background(), 2)),
+	})
+	if len(res.Errors) > 0 {
+		t.Fatal(res.Errors)
+	}
+	var data struct{ CreateTodo todoJSON }
+	decode(t, res, &data)
+	if data.CreateTodo.Id == first {
+		t.Errorf("another user's key returned todo %d of the anonymous user", first)
+	}
+}
+
+func TestCreateTodoIdempotentConcurrent(t *testing.T) {
+	env := newEnv(t)
+
+	ids := make(chan int, 5)
+	var wg sync.WaitGroup
+	for i := 0; i < cap(ids); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res := env.Do(createOnceQuery, map[string]interface{}{"key": "rent-june"})
+			if len(res.Errors) > 0 {
+				t.Error(res.Errors)
+				return
+			}
+			// decode may not fail the test from another goroutine
+			todo := res.Data.(map[string]interface{})["createTodo"].(map[string]interface{})
+			ids <- todo["Id"].(int)
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := map[int]bool{}
+	for id := range ids {
+		seen[id] = true
+	}
+	if len(seen) != 1 {
+		t.Errorf("concurrent retries returned todos %v, want a single one", seen)
+	}
+	if n := countTodos(t, env); n != 4 {
+		t.Errorf("%d todos, want the 3 seeded and one created", n)
+	}
+}
@@ -0,0 +1,121 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+var importTodoInput = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name:        "ImportTodoInput",
+	Description: "One todo to import",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"Text": &graphql.InputObjectFieldConfig{
+			Type:        graphql.String,
+			Description: "What needs to be done, required",
+		},
+		"Done": &graphql.InputObjectFieldConfig{
+			Type:        graphql.Boolean,
+			Description: "Whether the todo is already done",
+		},
+	},
+})
+
+var importErrorType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "ImportError",
+	Description: "Why a row of an import was rejected",
+	Fields: graphql.Fields{
+		"index": &graphql.Field{
+			Type:        graphql.Int,
+			Description: "Position of the row in the input, from 0",
+		},
+		"message": &graphql.Field{
+			Type: graphql.String,
+		},
+	},
+})
+
+var importResultType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "ImportResult",
+	Fields: graphql.Fields{
+		"imported": &graphql.Field{
+			Type:        graphql.Int,
+			Description: "How many todos were created",
+		},
+		"errors": &graphql.Field{
+			Type:        graphql.NewList(importErrorType),
+			Description: "Rows that were rejected",
+		},
+	},
+})
+
+// importResult is the source value for ImportResult.
+type importResult struct {
+	Imported int
+	Errors   []importError
+	// Todos are the created todos, for publishing once committed.
+	Todos []model.Todo
+}
+
+// importError is the source value for ImportError.
+type importError struct {
+	Index   int
+	Message string
+}
+
+// errStrictImport rolls a strict import back once a row was rejected.
+var errStrictImport = errors.New("strict import rejected a row")
+
+// importTodos creates a todo for every valid row in a single transaction.
+// Invalid rows are reported and skipped, unless strict is set, in which
+// case nothing is imported.
+func importTodos(ctx context.Context, s store.TodoStore, rows []interface{}, strict bool) (*importResult, error) {
+	result := &importResult{Errors: []importError{}}
+	err := s.WithTx(ctx, func(tx store.TodoStore) error {
+		result.Errors, result.Todos = result.Errors[:0], nil
+		for i, row := range rows {
+			fields, _ := row.(map[string]interface{})
+			text, _ := fields["Text"].(string)
+			if strings.TrimSpace(text) == "" {
+				result.Errors = append(result.Errors, importError{Index: i, Message: "Text is required"})
+				continue
+			}
+
+			todo := model.Todo{Text: text}
+			todo.SetStatus(model.StatusTodo)
+			if done, _ := fields["Done"].(bool); done {
+				todo.SetStatus(model.StatusDone)
+			}
+			err := tx.Create(ctx, &todo)
+			if err == store.ErrDuplicateText {
+				result.Errors = append(result.Errors, importError{Index: i, Message: err.Error()})
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("row %d: %v", i, err)
+			}
+			result.Todos = append(result.Todos, todo)
+		}
+
+		if strict && len(result.Errors) > 0 {
+			return errStrictImport
+		}
+		return nil
+	})
+
+	switch {
+	case err == errStrictImport:
+		result.Todos = nil
+	case err != nil:
+		return nil, err
+	}
+
+	result.Imported = len(result.Todos)
+	return result, nil
+}
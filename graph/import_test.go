@@ -0,0 +1,99 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph/graphtest"
+)
+
+type importResultJSON struct {
+	ImportTodos struct {
+		Imported int
+		Errors   []struct {
+			Index   int
+			Message string
+		}
+	}
+}
+
+const importQuery = `mutation($todos: [ImportTodoInput!]!, $strict: Boolean) {
+	importTodos(todos: $todos, strict: $strict) { imported errors { index message } }
+}`
+
+// countTodos returns how many todos env lists.
+func countTodos(t *testing.T, env *graphtest.Env) int {
+	t.Helper()
+
+	var data struct{ TodoList []todoJSON }
+	do(t, env, `{ todoList { Id } }`, nil, &data)
+	return len(data.TodoList)
+}
+
+func TestImportTodos(t *testing.T) {
+	env := newEnv(t)
+
+	var data importResultJSON
+	do(t, env, importQuery, map[string]interface{}{
+		"todos": []interface{}{
+			map[string]interface{}{"Text": "a"},
+			map[string]interface{}{"Text": "b", "Done": true},
+		},
+	}, &data)
+	if data.ImportTodos.Imported != 2 || len(data.ImportTodos.Errors) != 0 {
+		t.Errorf("importTodos = %+v", data.ImportTodos)
+	}
+
+	var list struct{ TodoList []todoJSON }
+	do(t, env, `{ todoList { Id Text Done Status } }`, nil, &list)
+	if n := len(list.TodoList); n != len(graphtest.Todos)+2 {
+		t.Fatalf("%d todos after the import, want %d", n, len(graphtest.Todos)+2)
+	}
+	if b := list.TodoList[len(list.TodoList)-1]; b.Text != "b" || !b.Done || b.Status != "DONE" {
+		t.Errorf("imported done todo = %+v", b)
+	}
+}
+
+func TestImportTodosPartial(t *testing.T) {
+	env := newEnv(t)
+
+	var data importResultJSON
+	do(t, env, importQuery, map[string]interface{}{
+		"todos": []interface{}{
+			map[string]interface{}{"Text": "a"},
+			map[string]interface{}{"Text": "  "},
+			map[string]interface{}{"Done": true},
+			map[string]interface{}{"Text": "d"},
+		},
+	}, &data)
+
+	got := data.ImportTodos
+	if got.Imported != 2 {
+		t.Errorf("imported = %d, want 2", got.Imported)
+	}
+	if len(got.Errors) != 2 || got.Errors[0].Index != 1 || got.Errors[1].Index != 2 || got.Errors[0].Message == "" {
+		t.Errorf("errors = %+v, want rows 1 and 2", got.Errors)
+	}
+	if n := countTodos(t, env); n != len(graphtest.Todos)+2 {
+		t.Errorf("%d todos after a partial import, want %d", n, len(graphtest.Todos)+2)
+	}
+}
+
+func TestImportTodosStrict(t *testing.T) {
+	env := newEnv(t)
+
+	var data importResultJSON
+	do(t, env, importQuery, map[string]interface{}{
+		"todos": []interface{}{
+			map[string]interface{}{"Text": "a"},
+			map[string]interface{}{"Text": ""},
+		},
+		"strict": true,
+	}, &data)
+
+	if got := data.ImportTodos; got.Imported != 0 || len(got.Errors) != 1 || got.Errors[0].Index != 1 {
+		t.Errorf("strict importTodos = %+v", got)
+	}
+	if n := countTodos(t, env); n != len(graphtest.Todos) {
+		t.Errorf("%d todos after a rejected strict import, want %d", n, len(graphtest.Todos))
+	}
+}
@@ -0,0 +1,135 @@
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/graphql-go/graphql"
+)
+
+// introspectionQuery is the query GraphQL tooling sends to download a
+// schema, as printed by graphql-js.
+const introspectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      locations
+      args { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  fields(includeDeprecated: true) {
+    name
+    description
+    args { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType { kind name }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// IntrospectSchema returns the introspection result of schema as JSON, in
+// the {"data": {"__schema": ...}} shape code generators read. Every list
+// of named things is sorted by name, so the output only changes when the
+// schema does.
+func IntrospectSchema(schema graphql.Schema) ([]byte, error) {
+	res := graphql.Do(graphql.Params{Schema: schema, RequestString: introspectionQuery})
+	if res.HasErrors() {
+		return nil, fmt.Errorf("introspecting the schema: %v", res.Errors)
+	}
+
+	sortByName(res.Data)
+	return json.Marshal(map[string]interface{}{"data": res.Data})
+}
+
+// sortByName sorts the lists in v whose elements all have a name, at any
+// depth. The executor lists types and input fields in map order.
+func sortByName(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			sortByName(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			sortByName(child)
+		}
+		for _, child := range v {
+			if _, ok := introspectedName(child); !ok {
+				return
+			}
+		}
+		sort.SliceStable(v, func(i, j int) bool {
+			a, _ := introspectedName(v[i])
+			b, _ := introspectedName(v[j])
+			return a < b
+		})
+	}
+}
+
+// introspectedName is the name of an introspected object.
+func introspectedName(v interface{}) (string, bool) {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	name, ok := obj["name"].(string)
+	return name, ok
+}
@@ -0,0 +1,66 @@
+package graph_test
+
+import "testing"
+
+type introspectedField struct {
+	Name              string
+	Description       string
+	IsDeprecated      bool
+	DeprecationReason string
+	Args              []struct {
+		Name        string
+		Description string
+	}
+}
+
+func TestIntrospectionDescriptions(t *testing.T) {
+	env := newEnv(t)
+
+	const fields = `fields(includeDeprecated: true) {
+		name description isDeprecated deprecationReason
+		args { name description }
+	}`
+	var data struct {
+		Query    struct{ Fields []introspectedField } `json:"query"`
+		Mutation struct{ Fields []introspectedField } `json:"mutation"`
+		Todo     struct{ Fields []introspectedField } `json:"todo"`
+	}
+	do(t, env, `{
+		query: __type(name: "RootQuery") { `+fields+` }
+		mutation: __type(name: "RootMutation") { `+fields+` }
+		todo: __type(name: "Todo") { `+fields+` }
+	}`, nil, &data)
+
+	for _, typ := range []struct {
+		name   string
+		fields []introspectedField
+	}{
+		{"RootQuery", data.Query.Fields},
+		{"RootMutation", data.Mutation.Fields},
+		{"Todo", data.Todo.Fields},
+	} {
+		if len(typ.fields) == 0 {
+			t.Errorf("%s has no fields", typ.name)
+		}
+		for _, f := range typ.fields {
+			if f.Description == "" {
+				t.Errorf("%s.%s has no description", typ.name, f.Name)
+			}
+			for _, arg := range f.Args {
+				if arg.Description == "" {
+					t.Errorf("argument %s of %s.%s has no description", arg.Name, typ.name, f.Name)
+				}
+			}
+		}
+	}
+
+	deprecated := map[string]string{}
+	for _, f := range data.Todo.Fields {
+		if f.IsDeprecated {
+			deprecated[f.Name] = f.DeprecationReason
+		}
+	}
+	if reason := deprecated["Done"]; reason == "" {
+		t.Errorf("Todo.Done is not deprecated with a reason, deprecated fields: %v", deprecated)
+	}
+}
@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+type loaderKey struct{}
+
+// loader caches todos, dependencies and tags for the duration of one
+// request, so resolving blockedBy, blocks or Tags on a list of todos costs
+// a couple of batched store calls instead of a few per todo.
+type loader struct {
+	mu         sync.Mutex
+	deps       []model.Dependency
+	depsLoaded bool
+	todos      map[int]model.Todo
+	missing    map[int]bool // looked up but not found
+	tags       map[int][]model.Tag
+}
+
+// WithLoader returns a copy of ctx carrying an empty per-request loader.
+// Resolvers still work without one, they just stop batching.
+func WithLoader(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loaderKey{}, &loader{})
+}
+
+// loaderFrom returns the request's loader, or a throwaway one.
+func loaderFrom(ctx context.Context) *loader {
+	if l, ok := ctx.Value(loaderKey{}).(*loader); ok {
+		return l
+	}
+	return &loader{}
+}
+
+// reset drops everything cached, for resolvers that just changed todos or
+// their dependencies.
+func (l *loader) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.deps = nil
+	l.depsLoaded = false
+	l.todos = nil
+	l.missing = nil
+	l.tags = nil
+}
+
+// load fetches the todos among ids that are not cached yet with a single
+// store call. The caller holds mu.
+func (l *loader) load(ctx context.Context, s store.TodoStore, ids []int) error {
+	if l.todos == nil {
+		l.todos = map[int]model.Todo{}
+		l.missing = map[int]bool{}
+	}
+
+	wanted := []int{}
+	for _, id := range ids {
+		if _, ok := l.todos[id]; !ok && !l.missing[id] {
+			wanted = append(wanted, id)
+		}
+	}
+	if len(wanted) == 0 {
+		return nil
+	}
+
+	todos, err := s.GetMany(ctx, wanted)
+	if err != nil {
+		return err
+	}
+	for _, todo := range todos {
+		l.todos[todo.Id] = todo
+	}
+	for _, id := range wanted {
+		if _, ok := l.todos[id]; !ok {
+			l.missing[id] = true
+		}
+	}
+
+	return nil
+}
+
+// todosByIds returns the todos with the given Ids in the same order, with
+// nil for Ids that have no todo.
+func (l *loader) todosByIds(ctx context.Context, s store.TodoStore, ids []int) ([]*model.Todo, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.load(ctx, s, ids); err != nil {
+		return nil, err
+	}
+
+	todos := make([]*model.Todo, len(ids))
+	for i, id := range ids {
+		if todo, ok := l.todos[id]; ok {
+			todos[i] = &todo
+		}
+	}
+
+	return todos, nil
+}
+
+// dependencies returns the user's dependencies, loading them and every
+// todo they refer to on first use.
+func (l *loader) dependencies(ctx context.Context, s store.TodoStore) ([]model.Dependency, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.depsLoaded {
+		return l.deps, nil
+	}
+
+	deps, err := s.Dependencies(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []int{}
+	for _, dep := range deps {
+		ids = append(ids, dep.TodoId, dep.DependsOn)
+	}
+	if err := l.load(ctx, s, ids); err != nil {
+		return nil, err
+	}
+
+	l.deps = deps
+	l.depsLoaded = true
+	return l.deps, nil
+}
+
+// todo returns a todo loaded by dependencies.
+func (l *loader) todo(id int) (model.Todo, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	todo, ok := l.todos[id]
+	return todo, ok
+}
+
+// tagsOf returns the tags of a todo, loading those of every todo on first
+// use.
+func (l *loader) tagsOf(ctx context.Context, s store.TodoStore, todoID int) ([]model.Tag, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.tags == nil {
+		tags, err := s.Tags(ctx)
+		if err != nil {
+			return nil, err
+		}
+		l.tags = tags
+	}
+
+	return l.tags[todoID], nil
+}
@@ -0,0 +1,150 @@
+package graph_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// countingStore counts the lookups the loader is meant to batch.
+type countingStore struct {
+	*store.MemoryStore
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (s *countingStore) count(method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[method]++
+}
+
+func (s *countingStore) Get(ctx context.Context, id int) (*model.Todo, error) {
+	s.count("Get")
+	return s.MemoryStore.Get(ctx, id)
+}
+
+func (s *countingStore) GetMany(ctx context.Context, ids []int) ([]model.Todo, error) {
+	s.count("GetMany")
+	return s.MemoryStore.GetMany(ctx, ids)
+}
+
+func (s *countingStore) Dependencies(ctx context.Context) ([]model.Dependency, error) {
+	s.count("Dependencies")
+	return s.MemoryStore.Dependencies(ctx)
+}
+
+func (s *countingStore) Tags(ctx context.Context) (map[int][]model.Tag, error) {
+	s.count("Tags")
+	return s.MemoryStore.Tags(ctx)
+}
+
+func TestLoaderBatches(t *testing.T) {
+	ctx := context.Background()
+	s := &countingStore{MemoryStore: store.NewMemoryStore()}
+	// ten todos, each depending on the one before and tagged
+	for id := 1; id <= 10; id++ {
+		if err := s.MemoryStore.Create(ctx, &model.Todo{Text: "todo", Status: model.StatusTodo}); err != nil {
+			t.Fatal(err)
+		}
+		if id > 1 {
+			if err := s.MemoryStore.AddDependency(ctx, &model.Dependency{TodoId: id, DependsOn: id - 1}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := s.MemoryStore.AddTag(ctx, id, "batch"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schema, err := graph.NewSchema(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		query string
+		want  map[string]int
+	}{
+		{`{ todoList { Id blockedBy { Id } blocks { Id } Tags { Name } } }`, map[string]int{"Dependencies": 1, "GetMany": 1, "Tags": 1}},
+		{`{ todosByIds(Ids: [1, 2, 3, 4, 5, 6, 7, 8, 9, 10]) { Id } }`, map[string]int{"GetMany": 1}},
+	} {
+		s.calls = map[string]int{}
+		res := graphql.Do(graphql.Params{
+			Schema:        schema,
+			RequestString: tc.query,
+			Context:       graph.WithLoader(ctx),
+		})
+		if res.HasErrors() {
+			t.Fatal(res.Errors)
+		}
+
+		for _, method := range []string{"Get", "GetMany", "Dependencies", "Tags"} {
+			if s.calls[method] != tc.want[method] {
+				t.Errorf("%s: %d %s calls, want %d", tc.query, s.calls[method], method, tc.want[method])
+			}
+		}
+	}
+}
+
+func TestTodosByIds(t *testing.T) {
+	ctx := context.Background()
+	s := &countingStore{MemoryStore: store.NewMemoryStore(), calls: map[string]int{}}
+	for _, text := range []string{"one", "two", "three"} {
+		if err := s.MemoryStore.Create(ctx, &model.Todo{Text: text, Status: model.StatusTodo}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	schema, err := graph.NewSchema(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// both lookups share one loader, as the fields of a request do
+	loaderCtx := graph.WithLoader(ctx)
+	lookup := func(query string, v interface{}) {
+		t.Helper()
+		res := graphql.Do(graphql.Params{Schema: schema, RequestString: query, Context: loaderCtx})
+		if res.HasErrors() {
+			t.Fatal(res.Errors)
+		}
+		decode(t, res, v)
+	}
+
+	var data struct{ TodosByIds []*todoJSON }
+	lookup(`{ todosByIds(Ids: [3, 1, 99, 1]) { Id Text } }`, &data)
+	want := []int{3, 1, 0, 1}
+	if len(data.TodosByIds) != len(want) {
+		t.Fatalf("todosByIds = %+v, want Ids %v", data.TodosByIds, want)
+	}
+	for i, id := range want {
+		switch {
+		case id == 0 && data.TodosByIds[i] != nil:
+			t.Errorf("todosByIds[%d] = %+v, want null", i, data.TodosByIds[i])
+		case id != 0 && (data.TodosByIds[i] == nil || data.TodosByIds[i].Id != id):
+			t.Errorf("todosByIds[%d] = %+v, want todo %d", i, data.TodosByIds[i], id)
+		}
+	}
+	if data.TodosByIds[1].Text != "one" || data.TodosByIds[3].Text != "one" {
+		t.Errorf("repeated Id answered %q and %q, want the same todo", data.TodosByIds[1].Text, data.TodosByIds[3].Text)
+	}
+	if s.calls["GetMany"] != 1 || s.calls["Get"] != 0 {
+		t.Errorf("%d GetMany and %d Get calls, want a single GetMany", s.calls["GetMany"], s.calls["Get"])
+	}
+
+	// Ids seen before, found or not, are served from the cache
+	lookup(`{ todosByIds(Ids: [1, 99, 3]) { Id } }`, &data)
+	if got := len(data.TodosByIds); got != 3 || data.TodosByIds[1] != nil {
+		t.Errorf("second lookup = %+v, want todos 1, null and 3", data.TodosByIds)
+	}
+	if s.calls["GetMany"] != 1 || s.calls["Get"] != 0 {
+		t.Errorf("%d GetMany and %d Get calls after the second lookup, want still one GetMany", s.calls["GetMany"], s.calls["Get"])
+	}
+}
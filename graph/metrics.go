@@ -0,0 +1,26 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/metrics"
+)
+
+// instrument makes every field of schema that has its own resolver report
+// to reg as "Type.field".
+func instrument(schema graphql.Schema, reg *metrics.Registry) {
+	wrapResolvers(schema, func(field string, resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+		return observe(reg, field, resolve)
+	})
+}
+
+func observe(reg *metrics.Registry, field string, resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (interface{}, error) {
+		start := time.Now()
+		result, err := resolve(p)
+		reg.Observe(field, time.Since(start), err)
+		return result, err
+	}
+}
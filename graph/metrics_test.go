@@ -0,0 +1,43 @@
+package graph_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/metrics"
+)
+
+func TestMetrics(t *testing.T) {
+	reg := metrics.NewRegistry()
+	env := newEnv(t, graph.WithMetrics(reg))
+
+	do(t, env, `{ todoList { Id } }`, nil, &struct{}{})
+	do(t, env, `{ todoList { Id } }`, nil, &struct{}{})
+	do(t, env, `{ todo(Id: 99) { Id } }`, nil, &struct{}{})
+	if res := env.Do(`{ todos(Ids: []) { Id } }`, nil); len(res.Errors) == 0 {
+		t.Fatal("todos(Ids: []) did not fail")
+	}
+
+	var buf bytes.Buffer
+	if _, err := reg.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		`graphql_field_calls_total{field="RootQuery.todoList"} 2`,
+		`graphql_field_errors_total{field="RootQuery.todoList"} 0`,
+		`graphql_field_calls_total{field="RootQuery.todo"} 1`,
+		`graphql_field_errors_total{field="RootQuery.todo"} 0`,
+		`graphql_field_calls_total{field="RootQuery.todos"} 1`,
+		`graphql_field_errors_total{field="RootQuery.todos"} 1`,
+		`graphql_field_duration_seconds_count{field="RootQuery.todoList"} 2`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("metrics miss %s:\n%s", line, buf.String())
+		}
+	}
+	if strings.Contains(buf.String(), `field="RootMutation.createTodo"`) {
+		t.Errorf("metrics list createTodo, which never ran:\n%s", buf.String())
+	}
+}
@@ -0,0 +1,498 @@
+package graph
+
+import (
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/pubsub"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// root mutation
+func newRootMutation(s store.TodoStore, t *types, o *options) *graphql.Object {
+	fields := graphql.Fields{
+		/*
+			curl -g 'http://localhost:8081/graphql?query=mutation+_{createTodo(Text:"My+new+todo"){Id,Text,Done}}'
+		*/
+		"createTodo": &graphql.Field{
+			Type:        t.todo, // the return type for this field
+			Description: "Create new todo",
+			Args: graphql.FieldConfigArgument{
+				"Text": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "What needs to be done",
+				},
+				"Status": &graphql.ArgumentConfig{
+					Type:         statusEnum,
+					Description:  "Initial status",
+					DefaultValue: model.StatusTodo,
+				},
+				"DueDate": &graphql.ArgumentConfig{
+					Type:        graphql.DateTime,
+					Description: "When the todo should be done by",
+				},
+				"Estimate": &graphql.ArgumentConfig{
+					Type:        graphql.Int,
+					Description: "Estimated effort in points",
+				},
+				"Priority": &graphql.ArgumentConfig{
+					Type:         priorityEnum,
+					Description:  "How urgent the todo is",
+					DefaultValue: model.PriorityMedium,
+				},
+				"clientMutationId": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "Idempotency key; retrying with the same key returns the todo created the first time",
+				},
+			},
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				text, _ := params.Args["Text"].(string)
+				status, _ := params.Args["Status"].(model.Status)
+
+				dueDate, _ := params.Args["DueDate"].(time.Time)
+				estimate, _ := params.Args["Estimate"].(int)
+				priority, _ := params.Args["Priority"].(model.Priority)
+
+				newTodo := &model.Todo{Text: text, DueDate: dueDate, Estimate: estimate, Priority: priority}
+				newTodo.SetStatus(status)
+
+				key, ok := params.Args["clientMutationId"].(string)
+				if !ok {
+					if err := s.Create(params.Context, newTodo); err != nil {
+						return nil, err
+					}
+				} else {
+					newTodo.ClientMutationId = &key
+					existing, err := createOnce(params.Context, s, newTodo)
+					if err != nil {
+						return nil, err
+					}
+					if existing != nil {
+						return existing, nil
+					}
+				}
+
+				loaderFrom(params.Context).reset()
+				o.broker.Publish(pubsub.Event{Type: pubsub.Created, Todo: *newTodo})
+				return newTodo, nil
+			},
+		},
+		/*
+			curl -g 'http://localhost:8081/graphql?query=mutation+_{updateTodo(Id:1,Done:true){affectedRows,todo{Id,Text,Done}}}'
+		*/
+		"updateTodo": &graphql.Field{
+			Type:        t.updateTodoPayload, // the return type for this field
+			Description: "Update existing todo. Only the arguments that are sent are written, the rest keep their stored value",
+			Args: graphql.FieldConfigArgument{
+				"Text": &graphql.ArgumentConfig{
+					Type:        graphql.String,
+					Description: "New text",
+				},
+				"Done": &graphql.ArgumentConfig{
+					Type:        graphql.Boolean,
+					Description: "Deprecated: use Status. Marks the todo done, or reopens it",
+				},
+				"Status": &graphql.ArgumentConfig{
+					Type:        statusEnum,
+					Description: "New status, takes precedence over Done",
+				},
+				"DueDate": &graphql.ArgumentConfig{
+					Type:        graphql.DateTime,
+					Description: "New due date",
+				},
+				"Estimate": &graphql.ArgumentConfig{
+					Type:        graphql.Int,
+					Description: "New estimate in points",
+				},
+				"Priority": &graphql.ArgumentConfig{
+					Type:        priorityEnum,
+					Description: "New priority",
+				},
+				"Id": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Id of the todo to update",
+				},
+			},
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				idParam, _ := params.Args["Id"].(int)
+
+				var todo *model.Todo
+				changed := false
+				err := s.WithTx(params.Context, func(tx store.TodoStore) error {
+					var err error
+					if todo, err = tx.Get(params.Context, idParam); err != nil {
+						return err
+					}
+					before := *todo
+
+					// only touch what the client actually sent; Status
+					// wins over Done when both are given
+					var cols columns
+					if text, ok := params.Args["Text"].(string); ok {
+						todo.Text = text
+						cols.add("text")
+					}
+					if done, ok := params.Args["Done"].(bool); ok {
+						todo.SetDone(done)
+						cols.add("done", "status")
+					}
+					if status, ok := params.Args["Status"].(model.Status); ok {
+						todo.SetStatus(status)
+						cols.add("done", "status")
+					}
+					if dueDate, ok := params.Args["DueDate"].(time.Time); ok {
+						todo.DueDate = dueDate
+						cols.add("due_date")
+					}
+					if estimate, ok := params.Args["Estimate"].(int); ok {
+						todo.Estimate = estimate
+						cols.add("estimate")
+					}
+					if priority, ok := params.Args["Priority"].(model.Priority); ok {
+						todo.Priority = priority
+						cols.add("priority")
+					}
+
+					if changed = !sameContent(before, *todo); !changed {
+						return nil
+					}
+					return tx.Update(params.Context, todo, cols...)
+				})
+				switch {
+				case err == store.ErrNotFound:
+					return &updateTodoPayload{}, nil
+				case err != nil:
+					return nil, err
+				case !changed:
+					return &updateTodoPayload{Todo: todo}, nil
+				}
+
+				loaderFrom(params.Context).reset()
+				o.broker.Publish(pubsub.Event{Type: pubsub.Updated, Todo: *todo})
+				return &updateTodoPayload{Todo: todo, AffectedRows: 1}, nil
+			},
+		},
+		/*
+			curl -g 'http://localhost:8081/graphql?query=mutation+_{addDependency(Id:2,dependsOn:1){Id,blockedBy{Id}}}'
+		*/
+		"addDependency": &graphql.Field{
+			Type:        t.todo, // the return type for this field
+			Description: "Make a todo depend on another, so it is blocked until that one is done",
+			Args: graphql.FieldConfigArgument{
+				"Id": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Id of the dependent todo",
+				},
+				"dependsOn": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Id of the todo that has to be done first",
+				},
+			},
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				idParam, _ := params.Args["Id"].(int)
+				dependsOn, _ := params.Args["dependsOn"].(int)
+
+				var todo *model.Todo
+				err := s.WithTx(params.Context, func(tx store.TodoStore) error {
+					var err error
+					todo, err = addDependency(params.Context, tx, idParam, dependsOn)
+					return err
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				loaderFrom(params.Context).reset()
+				return todo, nil
+			},
+		},
+		/*
+			curl -g 'http://localhost:8081/graphql?query=mutation+_{reorderTodo(Id:3,afterId:1){Id,Position}}'
+		*/
+		"reorderTodo": &graphql.Field{
+			Type:        t.todo, // the return type for this field
+			Description: "Move a todo in the manual order that todoList(sortBy:POSITION) follows",
+			Args: graphql.FieldConfigArgument{
+				"Id": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Id of the todo to move",
+				},
+				"afterId": &graphql.ArgumentConfig{
+					Type:        graphql.Int,
+					Description: "Id of the todo to place it after, to the top when omitted",
+				},
+			},
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				idParam, _ := params.Args["Id"].(int)
+				var afterID *int
+				if after, ok := params.Args["afterId"].(int); ok {
+					afterID = &after
+				}
+
+				var todo *model.Todo
+				err := s.WithTx(params.Context, func(tx store.TodoStore) error {
+					var err error
+					todo, err = reorderTodo(params.Context, tx, idParam, afterID)
+					return err
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				loaderFrom(params.Context).reset()
+				o.broker.Publish(pubsub.Event{Type: pubsub.Updated, Todo: *todo})
+				return todo, nil
+			},
+		},
+		/*
+			curl -g 'http://localhost:8081/graphql?query=mutation+_{addTag(todoId:1,name:"home"){Id,Tags{Id,Name}}}'
+		*/
+		"addTag": &graphql.Field{
+			Type:        t.todo, // the return type for this field
+			Description: "Attach a tag to a todo, reusing the tag if the name exists",
+			Args: graphql.FieldConfigArgument{
+				"todoId": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Id of the todo to tag",
+				},
+				"name": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "Name of the tag",
+				},
+			},
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				todoID, _ := params.Args["todoId"].(int)
+				name, _ := params.Args["name"].(string)
+				name = strings.TrimSpace(name)
+				if name == "" {
+					return nil, validationError("tag name must not be empty")
+				}
+
+				var todo *model.Todo
+				err := s.WithTx(params.Context, func(tx store.TodoStore) error {
+					var err error
+					if todo, err = tx.Get(params.Context, todoID); err != nil {
+						return err
+					}
+					return tx.AddTag(params.Context, todoID, name)
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				loaderFrom(params.Context).reset()
+				return todo, nil
+			},
+		},
+		/*
+			curl -g 'http://localhost:8081/graphql?query=mutation+_{setTagColor(tag:"home",color:"#ff8800"){Id,Name,Color}}'
+		*/
+		"setTagColor": &graphql.Field{
+			Type:        tagType,
+			Description: "Set the color of a tag, on every todo that carries it",
+			Args: graphql.FieldConfigArgument{
+				"tag": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "Name of the tag",
+				},
+				"color": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "Hex color, #rgb or #rrggbb",
+				},
+			},
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				name, _ := params.Args["tag"].(string)
+				color, _ := params.Args["color"].(string)
+				if !validColor(color) {
+					return nil, validationError("color must be a hex color like #ff8800, got %q", color)
+				}
+
+				tag, err := s.SetTagColor(params.Context, strings.TrimSpace(name), strings.ToLower(color))
+				if err != nil {
+					return nil, err
+				}
+
+				loaderFrom(params.Context).reset()
+				return *tag, nil
+			},
+		},
+		/*
+			curl -g 'http://localhost:8081/graphql?query=mutation+_{transitionStatus(Id:1,to:IN_PROGRESS){Id,Status}}'
+		*/
+		"transitionStatus": &graphql.Field{
+			Type:        t.todo, // the return type for this field
+			Description: "Move a todo to another status, following the allowed transitions",
+			Args: graphql.FieldConfigArgument{
+				"Id": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Id of the todo to move",
+				},
+				"to": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(statusEnum),
+					Description: "Status to move to",
+				},
+			},
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				idParam, _ := params.Args["Id"].(int)
+				to, _ := params.Args["to"].(model.Status)
+
+				var todo *model.Todo
+				err := s.WithTx(params.Context, func(tx store.TodoStore) error {
+					var err error
+					if todo, err = tx.Get(params.Context, idParam); err != nil {
+						return err
+					}
+
+					from := todo.Status
+					if !o.transitions.Allows(from, to) {
+						return conflictError("cannot move todo %d from %s to %s", todo.Id, from, to)
+					}
+
+					todo.SetStatus(to)
+					if err := tx.Update(params.Context, todo); err != nil {
+						return err
+					}
+
+					return tx.AddTransition(params.Context, &model.StatusTransition{
+						TodoId: todo.Id,
+						From:   from,
+						To:     to,
+						At:     time.Now().UTC(),
+					})
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				loaderFrom(params.Context).reset()
+				o.broker.Publish(pubsub.Event{Type: pubsub.Updated, Todo: *todo})
+				return todo, nil
+			},
+		},
+		/*
+			curl -g -H 'X-Admin-Key: secret' 'http://localhost:8081/graphql?query=mutation+_{truncateLongTexts(maxLen:80)}'
+		*/
+		"truncateLongTexts": &graphql.Field{
+			Type:        graphql.Int,
+			Description: "Admin only. Shorten every todo text longer than maxLen, returning how many changed",
+			Args: graphql.FieldConfigArgument{
+				"maxLen": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Maximum text length in characters, including the ellipsis",
+				},
+			},
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				if !IsAdmin(params.Context) {
+					return nil, errAdminRequired
+				}
+
+				maxLen, _ := params.Args["maxLen"].(int)
+				if maxLen < 1 {
+					return nil, validationError("maxLen must be positive, got %d", maxLen)
+				}
+
+				changed, err := s.TruncateTexts(params.Context, maxLen)
+				if err != nil {
+					return nil, err
+				}
+
+				loaderFrom(params.Context).reset()
+				for _, todo := range changed {
+					o.broker.Publish(pubsub.Event{Type: pubsub.Updated, Todo: todo})
+				}
+				return len(changed), nil
+			},
+		},
+		/*
+			curl -g 'http://localhost:8081/graphql?query=mutation+_{importTodos(todos:[{Text:"a"},{Text:"b",Done:true}]){imported,errors{index,message}}}'
+		*/
+		"importTodos": &graphql.Field{
+			Type:        importResultType,
+			Description: "Create many todos in one transaction, skipping invalid rows unless strict",
+			Args: graphql.FieldConfigArgument{
+				"todos": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(importTodoInput))),
+					Description: "Todos to create, for example the rows of an export",
+				},
+				"strict": &graphql.ArgumentConfig{
+					Type:         graphql.Boolean,
+					Description:  "Import nothing when any row is invalid",
+					DefaultValue: false,
+				},
+			},
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				rows, _ := params.Args["todos"].([]interface{})
+				strict, _ := params.Args["strict"].(bool)
+
+				result, err := importTodos(params.Context, s, rows, strict)
+				if err != nil {
+					return nil, err
+				}
+
+				loaderFrom(params.Context).reset()
+				for _, todo := range result.Todos {
+					o.broker.Publish(pubsub.Event{Type: pubsub.Created, Todo: todo})
+				}
+				return result, nil
+			},
+		},
+		/*
+			curl -g -H 'X-Admin-Key: secret' 'http://localhost:8081/graphql?query=mutation+_{integrityCheck}'
+		*/
+		"integrityCheck": &graphql.Field{
+			Type:        graphql.NewList(graphql.String),
+			Description: "Admin only. Verify the database is not corrupted, returning [\"ok\"] or the problems found",
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				if !IsAdmin(params.Context) {
+					return nil, errAdminRequired
+				}
+
+				return s.IntegrityCheck(params.Context)
+			},
+		},
+		/*
+			curl -g 'http://localhost:8081/graphql?query=mutation+_{deleteTodo(Id:1){Id,Text,Done}}'
+		*/
+		"deleteTodo": &graphql.Field{
+			Type:        t.todo, // the return type for this field
+			Description: "Delete existing todo, returning it as it was",
+			Args: graphql.FieldConfigArgument{
+				"Id": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.Int),
+					Description: "Id of the todo to delete",
+				},
+			},
+			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+				idParam, _ := params.Args["Id"].(int)
+
+				var todo *model.Todo
+				err := s.WithTx(params.Context, func(tx store.TodoStore) error {
+					var err error
+					if todo, err = tx.Get(params.Context, idParam); err != nil {
+						return err
+					}
+					return tx.Delete(params.Context, idParam)
+				})
+				if err != nil {
+					return nil, err
+				}
+
+				loaderFrom(params.Context).reset()
+				o.broker.Publish(pubsub.Event{Type: pubsub.Deleted, Todo: *todo})
+				return todo, nil
+			},
+		},
+	}
+
+	for _, field := range fields {
+		field.Resolve = mapStorageErrors(field.Resolve)
+	}
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:   "RootMutation",
+		Fields: fields,
+	})
+}
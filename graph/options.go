@@ -0,0 +1,60 @@
+package graph
+
+import (
+	"github.com/nevzatalkan/golang-graphql-todo-example/metrics"
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/pubsub"
+)
+
+// Option customizes the schema built by NewSchema.
+type Option func(*options)
+
+type options struct {
+	transitions model.Transitions
+	broker      *pubsub.Broker
+	metrics     *metrics.Registry
+	settings    []Setting
+}
+
+func defaultOptions() *options {
+	return &options{
+		transitions: model.DefaultTransitions(),
+	}
+}
+
+// WithTransitions replaces the state machine enforced by transitionStatus.
+func WithTransitions(transitions model.Transitions) Option {
+	return func(o *options) {
+		o.transitions = transitions
+	}
+}
+
+// WithBroker makes mutations publish a pubsub.Event after every todo they
+// create, update or delete, feeding the todoChanged subscription.
+func WithBroker(broker *pubsub.Broker) Option {
+	return func(o *options) {
+		o.broker = broker
+	}
+}
+
+// WithMetrics records the calls, errors and latency of every resolver in
+// reg, labeled by field.
+func WithMetrics(reg *metrics.Registry) Option {
+	return func(o *options) {
+		o.metrics = reg
+	}
+}
+
+// Setting is one entry of the configuration reported by the config query.
+type Setting struct {
+	Name  string
+	Value string
+}
+
+// WithSettings sets what the admin-only config query reports. Leave
+// secrets out or redact them, the values are returned as given.
+func WithSettings(settings []Setting) Option {
+	return func(o *options) {
+		o.settings = settings
+	}
+}
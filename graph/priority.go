@@ -0,0 +1,17 @@
+package graph
+
+import (
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// withPriority keeps the todos of priority p, preserving their order.
+func withPriority(todos []model.Todo, p model.Priority) []model.Todo {
+	matching := []model.Todo{}
+	for _, todo := range todos {
+		if todo.Priority == p {
+			matching = append(matching, todo)
+		}
+	}
+
+	return matching
+}
@@ -0,0 +1,37 @@
+package graph_test
+
+import "testing"
+
+func TestPriority(t *testing.T) {
+	env := newEnv(t)
+
+	var created struct{ A, B, C todoJSON }
+	do(t, env, `mutation {
+		A: createTodo(Text: "fix the leak", Priority: HIGH) { Id Priority }
+		B: createTodo(Text: "sort the mail", Priority: LOW) { Id Priority }
+		C: createTodo(Text: "water the plants") { Id Priority }
+	}`, nil, &created)
+	if created.A.Priority != "HIGH" || created.B.Priority != "LOW" || created.C.Priority != "MEDIUM" {
+		t.Errorf("created priorities %s, %s, %s, want HIGH, LOW and the default MEDIUM",
+			created.A.Priority, created.B.Priority, created.C.Priority)
+	}
+	do(t, env, `mutation { updateTodo(Id: 3, Priority: HIGH) { affectedRows } }`, nil, &struct{}{})
+
+	for _, tc := range []struct {
+		query string
+		want  []int
+	}{
+		{`{ todoList(byPriority: HIGH) { Id } }`, []int{3, 4}},
+		{`{ todoList(byPriority: MEDIUM) { Id } }`, []int{1, 2, 6}},
+		{`{ todoList(byPriority: LOW) { Id } }`, []int{5}},
+		// ties keep Id order both ways
+		{`{ todoList(sortBy: PRIORITY, sortOrder: DESC) { Id } }`, []int{3, 4, 1, 2, 6, 5}},
+		{`{ todoList(sortBy: PRIORITY) { Id } }`, []int{5, 1, 2, 6, 3, 4}},
+	} {
+		var data struct{ TodoList []todoJSON }
+		do(t, env, tc.query, nil, &data)
+		if got := todoIds(data.TodoList); !equalInts(got, tc.want) {
+			t.Errorf("%s = %v, want %v", tc.query, got, tc.want)
+		}
+	}
+}
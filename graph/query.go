@@ -0,0 +1,344 @@
+package graph
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// root query
+// Test with curl
+// curl -g 'http://localhost:8081/graphql?query={todoList{Id,Text,Done}}'
+func newRootQuery(s store.TodoStore, t *types, o *options) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "RootQuery",
+		Fields: graphql.Fields{
+
+			/*
+			   curl -g 'http://localhost:8081/graphql?query={todo(Id:1){Id,Text,Done}}'
+			*/
+			"todo": &graphql.Field{
+				Type:        t.todo,
+				Description: "Get single todo",
+				Args: graphql.FieldConfigArgument{
+					"Id": &graphql.ArgumentConfig{
+						Type:        graphql.Int,
+						Description: "Id of the todo to fetch",
+					},
+				},
+				Resolve: func(params graphql.ResolveParams) (interface{}, error) {
+					idQuery, isOK := params.Args["Id"].(int)
+					if !isOK {
+						return nil, nil
+					}
+
+					todo, err := s.Get(params.Context, idQuery)
+					if err == store.ErrNotFound {
+						return nil, nil
+					}
+					return todo, err
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8081/graphql?query={todos(Ids:[3,1,3]){Id,Text}}'
+			*/
+			"todos": todosField(s, t, ""),
+
+			// the old name of todos, kept for existing clients
+			"todosByIds": todosField(s, t, "Use todos"),
+
+			/*
+			   curl -g 'http://localhost:8081/graphql?query={todoList(sortBy:CREATED_AT,sortOrder:DESC){Id,Text,Done}}'
+			   curl -g 'http://localhost:8081/graphql?query={todoList(dueBefore:"2030-01-01T00:00:00Z"){Id,Text,DueDate}}'
+			   curl -g 'http://localhost:8081/graphql?query={todoList(byTag:"home"){Id,Text,Tags{Name}}}'
+			   curl -g 'http://localhost:8081/graphql?query={todoList(byPriority:HIGH){Id,Text,Priority}}'
+			   curl -g 'http://localhost:8081/graphql?query={todoList(sortBy:PRIORITY,sortOrder:DESC){Id,Text,Priority}}'
+			*/
+			"todoList": &graphql.Field{
+				Type:        graphql.NewList(t.todo),
+				Description: "List of todos, by Id ascending unless sortBy is given, with ties broken by Id",
+				Args: graphql.FieldConfigArgument{
+					"sortBy": &graphql.ArgumentConfig{
+						Type:         sortFieldEnum,
+						Description:  "Attribute to order by, Id when omitted",
+						DefaultValue: store.SortByID,
+					},
+					"sortOrder": &graphql.ArgumentConfig{
+						Type:         sortOrderEnum,
+						Description:  "Direction of the ordering",
+						DefaultValue: store.Asc,
+					},
+					"dueBefore": &graphql.ArgumentConfig{
+						Type:        graphql.String,
+						Description: "Only todos with a due date before this RFC 3339 time",
+					},
+					"byTag": &graphql.ArgumentConfig{
+						Type:        graphql.String,
+						Description: "Only todos carrying the tag with this name",
+					},
+					"byPriority": &graphql.ArgumentConfig{
+						Type:        priorityEnum,
+						Description: "Only todos of this priority",
+					},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					all, err := s.List(p.Context, sortArgs(p.Args))
+					if err != nil {
+						return nil, err
+					}
+
+					if v, ok := p.Args["dueBefore"].(string); ok {
+						before, err := time.Parse(time.RFC3339, v)
+						if err != nil {
+							return nil, validationError("dueBefore: %v", err)
+						}
+						all = dueBefore(all, before)
+					}
+
+					if name, ok := p.Args["byTag"].(string); ok {
+						tags, err := s.Tags(p.Context)
+						if err != nil {
+							return nil, err
+						}
+						all = taggedWith(all, tags, name)
+					}
+
+					if priority, ok := p.Args["byPriority"].(model.Priority); ok {
+						all = withPriority(all, priority)
+					}
+
+					return all, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8081/graphql?query={todosConnection(first:2,sortBy:TEXT){edges{cursor,node{Id,Text}},pageInfo{hasNextPage,endCursor}}}'
+			*/
+			"todosConnection": &graphql.Field{
+				Type:        t.todoConnection,
+				Description: "Cursor-paginated todos, by Id unless sortBy is given",
+				Args: graphql.FieldConfigArgument{
+					"first": &graphql.ArgumentConfig{
+						Type:         graphql.Int,
+						Description:  "Page size, at most 100",
+						DefaultValue: defaultPageSize,
+					},
+					"after": &graphql.ArgumentConfig{
+						Type:        graphql.String,
+						Description: "endCursor of the previous page",
+					},
+					"sortBy": &graphql.ArgumentConfig{
+						Type:         sortFieldEnum,
+						Description:  "Attribute to order by; cursors only work with the sortBy they were issued for",
+						DefaultValue: store.SortByID,
+					},
+					"sortOrder": &graphql.ArgumentConfig{
+						Type:         sortOrderEnum,
+						Description:  "Direction of the ordering",
+						DefaultValue: store.Asc,
+					},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					first, _ := p.Args["first"].(int)
+					if first < 1 || first > maxPageSize {
+						return nil, validationError("first must be between 1 and %d, got %d", maxPageSize, first)
+					}
+
+					order := sortArgs(p.Args)
+
+					var after *store.Keyset
+					if cursor, ok := p.Args["after"].(string); ok {
+						var err error
+						if after, err = decodeCursor(cursor, order); err != nil {
+							return nil, err
+						}
+					}
+
+					rows, err := s.ListAfter(p.Context, order, after, first+1)
+					if err != nil {
+						return nil, err
+					}
+
+					return newTodoConnection(rows, first, order), nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8081/graphql?query={longestTodos(first:3){Id,Text}}'
+			*/
+			"longestTodos": &graphql.Field{
+				Type:        graphql.NewList(t.todo),
+				Description: "Todos with the longest text first",
+				Args: graphql.FieldConfigArgument{
+					"first": &graphql.ArgumentConfig{
+						Type:         graphql.Int,
+						Description:  "Number of todos to return",
+						DefaultValue: 10,
+					},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					first, _ := p.Args["first"].(int)
+					if first < 1 {
+						return nil, validationError("first must be positive, got %d", first)
+					}
+
+					return s.Longest(p.Context, first)
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8081/graphql?query={randomTodo{Id,Text}}'
+			*/
+			"randomTodo": &graphql.Field{
+				Type:        t.todo,
+				Description: "A random todo that is not done yet, or null if there is none",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					todo, err := s.RandomActive(p.Context)
+					if err == store.ErrNotFound {
+						return nil, nil
+					}
+					return todo, err
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8081/graphql?query={ageDistribution{lessThanOneDay,oneToSevenDays,sevenToThirtyDays,moreThanThirtyDays}}'
+			*/
+			"ageDistribution": &graphql.Field{
+				Type:        ageDistributionType,
+				Description: "How long the todos that are not done have been open",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					all, err := s.List(p.Context, store.Sort{})
+					if err != nil {
+						return nil, err
+					}
+
+					return newAgeDistribution(all, time.Now()), nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8081/graphql?query={blockedTodos{Id,Text,blockedBy{Id,Status}}}'
+			*/
+			"blockedTodos": &graphql.Field{
+				Type:        graphql.NewList(t.todo),
+				Description: "Todos that cannot start because something they depend on is not done",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					all, err := s.List(p.Context, store.Sort{})
+					if err != nil {
+						return nil, err
+					}
+					deps, err := s.Dependencies(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					return blockedTodos(all, deps), nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8081/graphql?query={criticalPath{totalEstimate,todos{Id,Text,Estimate}}}'
+			*/
+			"criticalPath": &graphql.Field{
+				Type:        t.criticalPath,
+				Description: "The chain of open dependent todos with the biggest total estimate",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					all, err := s.List(p.Context, store.Sort{})
+					if err != nil {
+						return nil, err
+					}
+					deps, err := s.Dependencies(p.Context)
+					if err != nil {
+						return nil, err
+					}
+
+					return newCriticalPath(all, deps)
+				},
+			},
+
+			/*
+			   curl -g -H 'X-Admin-Key: secret' 'http://localhost:8081/graphql?query={config{name,value}}'
+			*/
+			"config": &graphql.Field{
+				Type:        graphql.NewList(settingType),
+				Description: "Admin only. The effective configuration, with secrets redacted",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if !IsAdmin(p.Context) {
+						return nil, errAdminRequired
+					}
+
+					return o.settings, nil
+				},
+			},
+
+			/*
+			   curl -g 'http://localhost:8081/graphql?query={board{todo{Id,Text},inProgress{Id,Text},done{Id,Text}}}'
+			*/
+			"board": &graphql.Field{
+				Type:        t.board,
+				Description: "All todos grouped into kanban columns",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					all, err := s.List(p.Context, store.Sort{})
+					if err != nil {
+						return nil, err
+					}
+
+					return newBoard(all), nil
+				},
+			},
+		},
+	})
+}
+
+// sortArgs reads the sortBy and sortOrder arguments. Both are enums, so
+// anything that gets here is already one of the allowed values.
+func sortArgs(args map[string]interface{}) store.Sort {
+	field, _ := args["sortBy"].(store.SortField)
+	order, _ := args["sortOrder"].(store.SortOrder)
+
+	return store.Sort{Field: field, Order: order}
+}
+
+// todosField looks up a batch of todos in one round trip, for clients
+// that would otherwise send one todo query per Id.
+func todosField(s store.TodoStore, t *types, deprecationReason string) *graphql.Field {
+	return &graphql.Field{
+		Type:              graphql.NewList(t.todo),
+		Description:       "Todos with the given Ids in the same order, null where there is none",
+		DeprecationReason: deprecationReason,
+		Args: graphql.FieldConfigArgument{
+			"Ids": &graphql.ArgumentConfig{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.Int))),
+				Description: fmt.Sprintf("Ids to look up, at least 1 and at most %d", maxPageSize),
+			},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			args, _ := p.Args["Ids"].([]interface{})
+			if len(args) == 0 {
+				return nil, validationError("Ids must not be empty")
+			}
+			if len(args) > maxPageSize {
+				return nil, validationError("at most %d Ids can be looked up at once, got %d", maxPageSize, len(args))
+			}
+
+			return loaderFrom(p.Context).todosByIds(p.Context, s, intArgs(args))
+		},
+	}
+}
+
+// intArgs converts a list argument of Ints.
+func intArgs(args []interface{}) []int {
+	ids := make([]int, 0, len(args))
+	for _, arg := range args {
+		id, _ := arg.(int)
+		ids = append(ids, id)
+	}
+
+	return ids
+}
@@ -0,0 +1,139 @@
+package graph_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+)
+
+func TestLongestTodos(t *testing.T) {
+	env := newEnv(t)
+	do(t, env, `mutation { createTodo(Text: "call mom") { Id } }`, nil, &struct{}{})
+
+	var data struct{ LongestTodos []todoJSON }
+	do(t, env, `{ longestTodos(first: 3) { Id } }`, nil, &data)
+
+	// "review the pull request" is longest; the two texts of equal
+	// length follow by Id, and the short new todo is cut off
+	if got, want := todoIds(data.LongestTodos), []int{2, 1, 3}; !equalInts(got, want) {
+		t.Errorf("longestTodos = %v, want %v", got, want)
+	}
+
+	res := env.Do(`{ longestTodos(first: 0) { Id } }`, nil)
+	if len(res.Errors) == 0 {
+		t.Error("longestTodos(first: 0) did not fail")
+	}
+}
+
+func TestRandomTodo(t *testing.T) {
+	env := newEnv(t)
+
+	seen := map[int]bool{}
+	for i := 0; i < 20; i++ {
+		var data struct{ RandomTodo *todoJSON }
+		do(t, env, `{ randomTodo { Id Status } }`, nil, &data)
+		if data.RandomTodo == nil {
+			t.Fatal("randomTodo = null with active todos around")
+		}
+		if data.RandomTodo.Status == "DONE" {
+			t.Fatalf("randomTodo returned done todo %d", data.RandomTodo.Id)
+		}
+		seen[data.RandomTodo.Id] = true
+	}
+	if seen[3] {
+		t.Error("randomTodo returned the done todo 3")
+	}
+
+	do(t, env, `mutation { a: updateTodo(Id: 1, Done: true) { affectedRows } b: updateTodo(Id: 2, Done: true) { affectedRows } }`, nil, &struct{}{})
+	var data struct{ RandomTodo *todoJSON }
+	do(t, env, `{ randomTodo { Id } }`, nil, &data)
+	if data.RandomTodo != nil {
+		t.Errorf("randomTodo = %+v with every todo done, want null", data.RandomTodo)
+	}
+}
+
+func TestOverdueAndDueBefore(t *testing.T) {
+	env := newEnv(t)
+	now := time.Now().UTC()
+	for _, due := range []time.Time{now.Add(-48 * time.Hour), now.Add(48 * time.Hour), now.Add(30 * 24 * time.Hour)} {
+		do(t, env, `mutation($due: DateTime) { createTodo(Text: "due", DueDate: $due) { Id } }`,
+			map[string]interface{}{"due": due.Format(time.RFC3339)}, &struct{}{})
+	}
+
+	var all struct {
+		TodoList []struct {
+			Id      int
+			Overdue bool
+		}
+	}
+	do(t, env, `{ todoList { Id Overdue } }`, nil, &all)
+	overdue := map[int]bool{}
+	for _, todo := range all.TodoList {
+		overdue[todo.Id] = todo.Overdue
+	}
+	// 1-3 have no due date, 4 is past due, 5 and 6 are due later
+	want := map[int]bool{1: false, 2: false, 3: false, 4: true, 5: false, 6: false}
+	for id, w := range want {
+		if overdue[id] != w {
+			t.Errorf("todo %d Overdue = %v, want %v", id, overdue[id], w)
+		}
+	}
+
+	var upcoming struct{ TodoList []todoJSON }
+	do(t, env, `query($before: String) { todoList(dueBefore: $before) { Id } }`,
+		map[string]interface{}{"before": now.Add(7 * 24 * time.Hour).Format(time.RFC3339)}, &upcoming)
+	if got, want := todoIds(upcoming.TodoList), []int{4, 5}; !equalInts(got, want) {
+		t.Errorf("todoList(dueBefore: in a week) = %v, want %v", got, want)
+	}
+
+	res := env.Do(`{ todoList(dueBefore: "next tuesday") { Id } }`, nil)
+	if code := errorCode(t, res); code != graph.CodeValidation {
+		t.Errorf("code for a malformed dueBefore = %q, want %q", code, graph.CodeValidation)
+	}
+}
+
+func TestTodos(t *testing.T) {
+	env := newEnv(t)
+
+	var data struct{ Todos []*todoJSON }
+	do(t, env, `{ todos(Ids: [3, 1, 99, 3]) { Id Text } }`, nil, &data)
+
+	want := []int{3, 1, 0, 3}
+	if len(data.Todos) != len(want) {
+		t.Fatalf("todos = %+v, want Ids %v", data.Todos, want)
+	}
+	for i, id := range want {
+		switch {
+		case id == 0 && data.Todos[i] != nil:
+			t.Errorf("todos[%d] = %+v, want null for a missing Id", i, data.Todos[i])
+		case id != 0 && (data.Todos[i] == nil || data.Todos[i].Id != id):
+			t.Errorf("todos[%d] = %+v, want todo %d", i, data.Todos[i], id)
+		}
+	}
+
+	ids := func(n int) []interface{} {
+		ids := make([]interface{}, n)
+		for i := range ids {
+			ids[i] = i + 1
+		}
+		return ids
+	}
+	query := `query ($ids: [Int!]!) { todos(Ids: $ids) { Id } }`
+
+	do(t, env, query, map[string]interface{}{"ids": ids(100)}, &data)
+	if len(data.Todos) != 100 {
+		t.Errorf("todos of 100 Ids returned %d entries", len(data.Todos))
+	}
+	// todosByIds is the same field under its old name
+	for _, field := range []string{"todos", "todosByIds"} {
+		query := fmt.Sprintf(`query ($ids: [Int!]!) { %s(Ids: $ids) { Id } }`, field)
+		for _, n := range []int{0, 101} {
+			res := env.Do(query, map[string]interface{}{"ids": ids(n)})
+			if code := errorCode(t, res); code != graph.CodeValidation {
+				t.Errorf("%s of %d Ids: code = %q, want %q", field, n, code, graph.CodeValidation)
+			}
+		}
+	}
+}
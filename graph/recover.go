@@ -0,0 +1,27 @@
+package graph
+
+import (
+	"log"
+	"runtime/debug"
+
+	"github.com/graphql-go/graphql"
+)
+
+// errInternal is what clients see of a resolver that panicked; the
+// details only go to the log.
+var errInternal = &codedError{code: CodeInternal, message: "internal server error"}
+
+// recovering makes resolve report a panic as errInternal on its own field,
+// logging the stack, instead of failing the whole request.
+func recovering(field string, resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(p graphql.ResolveParams) (result interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("ERROR: panic resolving %s: %v\n%s", field, r, debug.Stack())
+				result, err = nil, errInternal
+			}
+		}()
+
+		return resolve(p)
+	}
+}
@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// reorderTodo moves the todo right after the one with afterID in position
+// order, or to the top when afterID is nil. Only the moved todo is written,
+// unless its new neighbours sit too close for a position between them; then
+// every other todo of the user is renumbered first.
+func reorderTodo(ctx context.Context, tx store.TodoStore, id int, afterID *int) (*model.Todo, error) {
+	if afterID != nil && *afterID == id {
+		return nil, validationError("todo %d cannot be moved after itself", id)
+	}
+
+	all, err := tx.List(ctx, store.Sort{Field: store.SortByPosition})
+	if err != nil {
+		return nil, err
+	}
+
+	var todo *model.Todo
+	others := make([]model.Todo, 0, len(all))
+	for i := range all {
+		if all[i].Id == id {
+			todo = &all[i]
+		} else {
+			others = append(others, all[i])
+		}
+	}
+	if todo == nil {
+		return nil, store.ErrNotFound
+	}
+
+	index := 0
+	if afterID != nil {
+		index = -1
+		for i, other := range others {
+			if other.Id == *afterID {
+				index = i + 1
+			}
+		}
+		if index < 0 {
+			return nil, store.ErrNotFound
+		}
+	}
+
+	position, ok := positionAt(others, index)
+	if !ok {
+		for i := range others {
+			others[i].Position = float64(i + 1)
+			if err := tx.Update(ctx, &others[i], "position"); err != nil {
+				return nil, err
+			}
+		}
+		position, _ = positionAt(others, index)
+	}
+
+	todo.Position = position
+	if err := tx.Update(ctx, todo, "position"); err != nil {
+		return nil, err
+	}
+
+	return todo, nil
+}
+
+// positionAt returns the position for a todo inserted at index into todos,
+// which are in position order. It reports false when the neighbours are
+// too close for a float between them.
+func positionAt(todos []model.Todo, index int) (float64, bool) {
+	switch {
+	case len(todos) == 0:
+		return 1, true
+	case index == 0:
+		return todos[0].Position - 1, true
+	case index == len(todos):
+		return todos[index-1].Position + 1, true
+	}
+
+	prev, next := todos[index-1].Position, todos[index].Position
+	mid := prev + (next-prev)/2
+	return mid, prev < mid && mid < next
+}
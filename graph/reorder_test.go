@@ -0,0 +1,66 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph/graphtest"
+)
+
+// positionOrder returns the Ids of env's todos in manual order.
+func positionOrder(t *testing.T, env *graphtest.Env) []int {
+	t.Helper()
+
+	var data struct{ TodoList []todoJSON }
+	do(t, env, `{ todoList(sortBy: POSITION) { Id } }`, nil, &data)
+	return todoIds(data.TodoList)
+}
+
+func TestReorderTodo(t *testing.T) {
+	env := newEnv(t)
+	do(t, env, `mutation { createTodo(Text: "pack the bags") { Id } }`, nil, &struct{}{})
+
+	for _, tc := range []struct {
+		move string
+		want []int
+	}{
+		{`mutation { reorderTodo(Id: 3) { Id } }`, []int{3, 1, 2, 4}},
+		{`mutation { reorderTodo(Id: 4, afterId: 3) { Id } }`, []int{3, 4, 1, 2}},
+		{`mutation { reorderTodo(Id: 3, afterId: 2) { Id } }`, []int{4, 1, 2, 3}},
+	} {
+		do(t, env, tc.move, nil, &struct{}{})
+		if got := positionOrder(t, env); !equalInts(got, tc.want) {
+			t.Errorf("%s: order %v, want %v", tc.move, got, tc.want)
+		}
+	}
+
+	for _, tc := range []struct {
+		move string
+		code string
+	}{
+		{`mutation { reorderTodo(Id: 2, afterId: 2) { Id } }`, graph.CodeValidation},
+		{`mutation { reorderTodo(Id: 404) { Id } }`, graph.CodeNotFound},
+		{`mutation { reorderTodo(Id: 2, afterId: 404) { Id } }`, graph.CodeNotFound},
+	} {
+		if code := errorCode(t, env.Do(tc.move, nil)); code != tc.code {
+			t.Errorf("%s: code = %q, want %q", tc.move, code, tc.code)
+		}
+	}
+}
+
+func TestReorderTodoRenumbers(t *testing.T) {
+	env := newEnv(t)
+
+	// every move halves the gap after todo 1, until a float no longer
+	// fits in it and the todos are renumbered
+	for i := 0; i < 80; i++ {
+		id := 2 + i%2
+		do(t, env, `mutation ($id: Int!) { reorderTodo(Id: $id, afterId: 1) { Id } }`,
+			map[string]interface{}{"id": id}, &struct{}{})
+
+		order := positionOrder(t, env)
+		if len(order) != 3 || order[0] != 1 || order[1] != id {
+			t.Fatalf("move %d: order %v, want todo %d right after 1", i+1, order, id)
+		}
+	}
+}
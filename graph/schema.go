@@ -0,0 +1,57 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// NewSchema builds the GraphQL schema with resolvers backed by s.
+func NewSchema(s store.TodoStore, opts ...Option) (graphql.Schema, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	t := newTypes(s)
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:        newRootQuery(s, t, o),
+		Mutation:     newRootMutation(s, t, o),
+		Subscription: newRootSubscription(t),
+	})
+	if err != nil {
+		return schema, err
+	}
+
+	wrapResolvers(schema, recovering)
+	wrapResolvers(schema, func(field string, resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+		return withErrorCodes(resolve)
+	})
+	if o.metrics != nil {
+		instrument(schema, o.metrics)
+	}
+
+	return schema, nil
+}
+
+// wrapResolvers replaces every field of schema that has its own resolver
+// with wrap applied to it, naming the field "Type.field". Fields read
+// straight off the source keep the default resolver.
+func wrapResolvers(schema graphql.Schema, wrap func(field string, resolve graphql.FieldResolveFn) graphql.FieldResolveFn) {
+	for name, typ := range schema.TypeMap() {
+		obj, ok := typ.(*graphql.Object)
+		if !ok || strings.HasPrefix(name, "__") {
+			continue
+		}
+
+		for fieldName, field := range obj.Fields() {
+			if field.Resolve == nil {
+				continue
+			}
+			field.Resolve = wrap(name+"."+fieldName, field.Resolve)
+		}
+	}
+}
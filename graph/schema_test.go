@@ -0,0 +1,232 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-xorm/xorm"
+	"github.com/graphql-go/graphql"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph/graphtest"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// todoJSON is a todo as the schema returns it.
+type todoJSON struct {
+	Id       int
+	Text     string
+	Done     bool
+	Status   string
+	Priority string
+}
+
+func newEnv(t *testing.T, opts ...graph.Option) *graphtest.Env {
+	t.Helper()
+
+	env, err := graphtest.New(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { env.Close() })
+
+	return env
+}
+
+// do runs query against env, fails the test on any error and decodes the
+// data into v.
+func do(t *testing.T, env *graphtest.Env, query string, variables map[string]interface{}, v interface{}) {
+	t.Helper()
+
+	res := env.Do(query, variables)
+	if len(res.Errors) > 0 {
+		t.Fatalf("%s: %v", query, res.Errors)
+	}
+	decode(t, res, v)
+}
+
+// decode round-trips the data of res through JSON into v, the way a
+// client would read it.
+func decode(t *testing.T, res *graphql.Result, v interface{}) {
+	t.Helper()
+
+	b, err := json.Marshal(res.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatalf("decoding %s: %v", b, err)
+	}
+}
+
+// errorCode returns the code of the only error of res.
+func errorCode(t *testing.T, res *graphql.Result) string {
+	t.Helper()
+
+	if len(res.Errors) != 1 {
+		t.Fatalf("want exactly one error, got %v", res.Errors)
+	}
+	code, _ := res.Errors[0].Extensions["code"].(string)
+	return code
+}
+
+func TestNewSchema(t *testing.T) {
+	engine, err := xorm.NewEngine("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetMaxOpenConns(1)
+	s, err := store.NewXormStore(engine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	schema, err := graph.NewSchema(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := func(query string) *graphql.Result {
+		return graphql.Do(graphql.Params{
+			Schema:        schema,
+			RequestString: query,
+			Context:       graph.WithLoader(context.Background()),
+		})
+	}
+
+	var empty struct{ TodoList []todoJSON }
+	decode(t, run(`{ todoList { Id } }`), &empty)
+	if len(empty.TodoList) != 0 {
+		t.Errorf("todoList of a fresh database = %+v", empty.TodoList)
+	}
+
+	res := run(`mutation { createTodo(Text: "first") { Id } }`)
+	if len(res.Errors) > 0 {
+		t.Fatal(res.Errors)
+	}
+
+	var list struct{ TodoList []todoJSON }
+	decode(t, run(`{ todoList { Id Text } }`), &list)
+	if len(list.TodoList) != 1 || list.TodoList[0].Text != "first" {
+		t.Errorf("todoList = %+v, want the created todo", list.TodoList)
+	}
+}
+
+func TestCreateTodo(t *testing.T) {
+	env := newEnv(t)
+
+	var data struct{ CreateTodo todoJSON }
+	do(t, env, `mutation { createTodo(Text: "water the plants") { Id Text Done Status } }`, nil, &data)
+
+	got := data.CreateTodo
+	if got.Id != len(graphtest.Todos)+1 || got.Text != "water the plants" || got.Done || got.Status != "TODO" {
+		t.Errorf("createTodo = %+v", got)
+	}
+
+	stored, err := env.Store.Get(context.Background(), got.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Text != "water the plants" {
+		t.Errorf("stored text = %q", stored.Text)
+	}
+}
+
+func TestTodo(t *testing.T) {
+	env := newEnv(t)
+
+	var data struct{ Todo *todoJSON }
+	do(t, env, `query($id: Int) { todo(Id: $id) { Id Text Status } }`, map[string]interface{}{"id": 2}, &data)
+	if data.Todo == nil || data.Todo.Text != graphtest.Todos[1].Text || data.Todo.Status != "IN_PROGRESS" {
+		t.Errorf("todo(Id: 2) = %+v", data.Todo)
+	}
+}
+
+func TestTodoNotFound(t *testing.T) {
+	env := newEnv(t)
+
+	var data struct{ Todo *todoJSON }
+	do(t, env, `{ todo(Id: 404) { Id } }`, nil, &data)
+	if data.Todo != nil {
+		t.Errorf("todo(Id: 404) = %+v, want null", data.Todo)
+	}
+}
+
+func TestTodoList(t *testing.T) {
+	env := newEnv(t)
+
+	var data struct{ TodoList []todoJSON }
+	do(t, env, `{ todoList { Id Text } }`, nil, &data)
+
+	if len(data.TodoList) != len(graphtest.Todos) {
+		t.Fatalf("todoList has %d todos, want %d", len(data.TodoList), len(graphtest.Todos))
+	}
+	for i, todo := range data.TodoList {
+		if todo.Id != i+1 || todo.Text != graphtest.Todos[i].Text {
+			t.Errorf("todoList[%d] = %+v", i, todo)
+		}
+	}
+}
+
+func TestUpdateTodo(t *testing.T) {
+	env := newEnv(t)
+
+	var data struct {
+		UpdateTodo struct {
+			AffectedRows int
+			Todo         todoJSON
+		}
+	}
+	do(t, env, `mutation { updateTodo(Id: 1, Status: DONE) { affectedRows todo { Id Text Done Status } } }`, nil, &data)
+
+	got := data.UpdateTodo
+	if got.AffectedRows != 1 {
+		t.Errorf("affectedRows = %d, want 1", got.AffectedRows)
+	}
+	if got.Todo.Text != graphtest.Todos[0].Text || !got.Todo.Done || got.Todo.Status != "DONE" {
+		t.Errorf("updateTodo = %+v", got.Todo)
+	}
+}
+
+func TestUpdateTodoNotFound(t *testing.T) {
+	env := newEnv(t)
+
+	var data struct {
+		UpdateTodo struct {
+			AffectedRows int
+			Todo         *todoJSON
+		}
+	}
+	do(t, env, `mutation { updateTodo(Id: 404, Done: true) { affectedRows todo { Id } } }`, nil, &data)
+	if data.UpdateTodo.AffectedRows != 0 || data.UpdateTodo.Todo != nil {
+		t.Errorf("updateTodo(Id: 404) = %+v, want no rows and a null todo", data.UpdateTodo)
+	}
+}
+
+func TestDeleteTodo(t *testing.T) {
+	env := newEnv(t)
+
+	var data struct{ DeleteTodo todoJSON }
+	do(t, env, `mutation { deleteTodo(Id: 3) { Id Text } }`, nil, &data)
+	if data.DeleteTodo.Id != 3 || data.DeleteTodo.Text != graphtest.Todos[2].Text {
+		t.Errorf("deleteTodo = %+v", data.DeleteTodo)
+	}
+
+	var after struct{ Todo *todoJSON }
+	do(t, env, `{ todo(Id: 3) { Id } }`, nil, &after)
+	if after.Todo != nil {
+		t.Errorf("todo 3 still there after deleteTodo: %+v", after.Todo)
+	}
+}
+
+func TestDeleteTodoNotFound(t *testing.T) {
+	env := newEnv(t)
+
+	res := env.Do(`mutation { deleteTodo(Id: 404) { Id } }`, nil)
+	if code := errorCode(t, res); code != graph.CodeNotFound {
+		t.Errorf("code = %q, want %q", code, graph.CodeNotFound)
+	}
+}
@@ -0,0 +1,182 @@
+package graph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+)
+
+// specScalars are built into every GraphQL implementation and left out of
+// printed schemas.
+var specScalars = map[string]bool{
+	"String":  true,
+	"Int":     true,
+	"Float":   true,
+	"Boolean": true,
+	"ID":      true,
+}
+
+// PrintSchema renders schema in the GraphQL schema definition language,
+// types and fields sorted by name so the output only changes when the
+// schema does.
+func PrintSchema(schema graphql.Schema) string {
+	var b bytes.Buffer
+
+	b.WriteString("schema {\n")
+	if t := schema.QueryType(); t != nil {
+		fmt.Fprintf(&b, "  query: %s\n", t.Name())
+	}
+	if t := schema.MutationType(); t != nil {
+		fmt.Fprintf(&b, "  mutation: %s\n", t.Name())
+	}
+	if t := schema.SubscriptionType(); t != nil {
+		fmt.Fprintf(&b, "  subscription: %s\n", t.Name())
+	}
+	b.WriteString("}\n")
+
+	typeMap := schema.TypeMap()
+	names := make([]string, 0, len(typeMap))
+	for name := range typeMap {
+		if !strings.HasPrefix(name, "__") && !specScalars[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		b.WriteString("\n")
+		printType(&b, typeMap[name])
+	}
+
+	return b.String()
+}
+
+func printType(b *bytes.Buffer, typ graphql.Type) {
+	printDescription(b, "", typ.Description())
+
+	switch t := typ.(type) {
+	case *graphql.Scalar:
+		fmt.Fprintf(b, "scalar %s\n", t.Name())
+	case *graphql.Enum:
+		fmt.Fprintf(b, "enum %s {\n", t.Name())
+		values := t.Values()
+		sort.Slice(values, func(i, j int) bool { return values[i].Name < values[j].Name })
+		for _, v := range values {
+			printDescription(b, "  ", v.Description)
+			fmt.Fprintf(b, "  %s%s\n", v.Name, deprecated(v.DeprecationReason))
+		}
+		b.WriteString("}\n")
+	case *graphql.InputObject:
+		fmt.Fprintf(b, "input %s {\n", t.Name())
+		fields := t.Fields()
+		names := make([]string, 0, len(fields))
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			f := fields[name]
+			printDescription(b, "  ", f.Description())
+			fmt.Fprintf(b, "  %s: %s%s\n", f.Name(), f.Type, defaultValue(f.Type, f.DefaultValue))
+		}
+		b.WriteString("}\n")
+	case *graphql.Object:
+		fmt.Fprintf(b, "type %s%s {\n", t.Name(), implements(t.Interfaces()))
+		printFields(b, t.Fields())
+		b.WriteString("}\n")
+	case *graphql.Interface:
+		fmt.Fprintf(b, "interface %s {\n", t.Name())
+		printFields(b, t.Fields())
+		b.WriteString("}\n")
+	case *graphql.Union:
+		members := make([]string, 0, len(t.Types()))
+		for _, member := range t.Types() {
+			members = append(members, member.Name())
+		}
+		fmt.Fprintf(b, "union %s = %s\n", t.Name(), strings.Join(members, " | "))
+	}
+}
+
+func printFields(b *bytes.Buffer, fields graphql.FieldDefinitionMap) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := fields[name]
+		printDescription(b, "  ", f.Description)
+		fmt.Fprintf(b, "  %s%s: %s%s\n", f.Name, printArgs(f.Args), f.Type, deprecated(f.DeprecationReason))
+	}
+}
+
+func printArgs(args []*graphql.Argument) string {
+	if len(args) == 0 {
+		return ""
+	}
+
+	sorted := append([]*graphql.Argument(nil), args...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name() < sorted[j].Name() })
+
+	printed := make([]string, 0, len(sorted))
+	for _, arg := range sorted {
+		printed = append(printed, fmt.Sprintf("%s: %s%s", arg.Name(), arg.Type, defaultValue(arg.Type, arg.DefaultValue)))
+	}
+	return "(" + strings.Join(printed, ", ") + ")"
+}
+
+// defaultValue renders " = value" for a default, using the name of enum
+// values rather than what they resolve to.
+func defaultValue(typ graphql.Input, v interface{}) string {
+	if v == nil {
+		return ""
+	}
+
+	if enum, ok := typ.(*graphql.Enum); ok {
+		for _, value := range enum.Values() {
+			if value.Value == v {
+				return " = " + value.Name
+			}
+		}
+	}
+	if s, ok := v.(string); ok {
+		return " = " + quote(s)
+	}
+	return fmt.Sprintf(" = %v", v)
+}
+
+func implements(interfaces []*graphql.Interface) string {
+	if len(interfaces) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(interfaces))
+	for _, i := range interfaces {
+		names = append(names, i.Name())
+	}
+	return " implements " + strings.Join(names, " & ")
+}
+
+func deprecated(reason string) string {
+	if reason == "" {
+		return ""
+	}
+	return " @deprecated(reason: " + quote(reason) + ")"
+}
+
+func printDescription(b *bytes.Buffer, indent, description string) {
+	if description != "" {
+		fmt.Fprintf(b, "%s%s\n", indent, quote(description))
+	}
+}
+
+// quote renders s as a GraphQL string. JSON escapes are valid there too.
+func quote(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
@@ -0,0 +1,45 @@
+package graph_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+)
+
+func TestPrintSchema(t *testing.T) {
+	env := newEnv(t)
+	sdl := graph.PrintSchema(env.Schema)
+
+	for _, want := range []string{
+		"schema {\n  query: RootQuery\n  mutation: RootMutation\n",
+		"\ntype Todo {\n",
+		"\ntype RootQuery {\n",
+		"\ntype RootMutation {\n",
+		"\n  todo(Id: Int): Todo\n",
+		"\n  deleteTodo(Id: Int!): Todo\n",
+		"\nenum Priority {\n",
+	} {
+		if !strings.Contains(sdl, want) {
+			t.Errorf("schema misses %q", want)
+		}
+	}
+
+	// every query and mutation field is listed
+	for _, fields := range []graphql.FieldDefinitionMap{
+		env.Schema.QueryType().Fields(),
+		env.Schema.MutationType().Fields(),
+	} {
+		for name := range fields {
+			if !strings.Contains(sdl, "\n  "+name+"(") && !strings.Contains(sdl, "\n  "+name+": ") {
+				t.Errorf("schema misses the field %s", name)
+			}
+		}
+	}
+
+	if again := graph.PrintSchema(env.Schema); again != sdl {
+		t.Error("printing the schema twice gave different output")
+	}
+}
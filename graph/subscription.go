@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/pubsub"
+)
+
+// EventKey is the key of the root object entry holding the pubsub.Event a
+// subscription operation is executed for.
+const EventKey = "event"
+
+var changeTypeEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "TodoChangeType",
+	Description: "What happened to a todo",
+	Values: graphql.EnumValueConfigMap{
+		"CREATED": &graphql.EnumValueConfig{
+			Value: pubsub.Created,
+		},
+		"UPDATED": &graphql.EnumValueConfig{
+			Value: pubsub.Updated,
+		},
+		"DELETED": &graphql.EnumValueConfig{
+			Value: pubsub.Deleted,
+		},
+	},
+})
+
+// root subscription
+// Subscription operations run once per published event with the event in
+// the root object under EventKey, see the /subscriptions endpoint.
+func newRootSubscription(t *types) *graphql.Object {
+	eventType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoChangeEvent",
+		Fields: graphql.Fields{
+			"type": &graphql.Field{
+				Type: changeTypeEnum,
+			},
+			"todo": &graphql.Field{
+				Type: t.todo,
+			},
+		},
+	})
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "RootSubscription",
+		Fields: graphql.Fields{
+			/*
+				subscription { todoChanged { type, todo { Id, Text, Status } } }
+			*/
+			"todoChanged": &graphql.Field{
+				Type:        eventType,
+				Description: "Pushed whenever a todo is created, updated or deleted",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					root, _ := p.Info.RootValue.(map[string]interface{})
+					return root[EventKey], nil
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,30 @@
+package graph
+
+import (
+	"regexp"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// validColor reports whether color is a #rgb or #rrggbb hex color.
+func validColor(color string) bool {
+	return hexColor.MatchString(color)
+}
+
+// taggedWith keeps the todos that carry the tag called name, preserving
+// their order. tags maps todo Ids to their tags as returned by the store.
+func taggedWith(todos []model.Todo, tags map[int][]model.Tag, name string) []model.Todo {
+	tagged := []model.Todo{}
+	for _, todo := range todos {
+		for _, tag := range tags[todo.Id] {
+			if tag.Name == name {
+				tagged = append(tagged, todo)
+				break
+			}
+		}
+	}
+
+	return tagged
+}
@@ -0,0 +1,133 @@
+package graph_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+)
+
+func TestTags(t *testing.T) {
+	env := newEnv(t)
+
+	for _, tag := range []struct {
+		todoId int
+		name   string
+	}{
+		{1, "home"},
+		{1, "work"},
+		{3, "home"},
+		// tagging twice changes nothing
+		{3, "home"},
+	} {
+		do(t, env, `mutation ($id: Int!, $name: String!) { addTag(todoId: $id, name: $name) { Id } }`,
+			map[string]interface{}{"id": tag.todoId, "name": tag.name}, &struct{}{})
+	}
+
+	var data struct {
+		Todo struct{ Tags []struct{ Name string } }
+	}
+	do(t, env, `{ todo(Id: 3) { Tags { Name } } }`, nil, &data)
+	if len(data.Todo.Tags) != 1 || data.Todo.Tags[0].Name != "home" {
+		t.Errorf("todo 3 tags = %+v, want only home", data.Todo.Tags)
+	}
+
+	tags, err := env.Store.Tags(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags[1]) != 2 || len(tags[3]) != 1 {
+		t.Fatalf("tags = %+v, want two on todo 1 and one on todo 3", tags)
+	}
+	for _, tag := range tags[1] {
+		if tag.Name == "home" && tag.Id != tags[3][0].Id {
+			t.Errorf("home has Ids %d and %d, want the tag reused", tag.Id, tags[3][0].Id)
+		}
+	}
+
+	for _, tc := range []struct {
+		tag  string
+		want []int
+	}{
+		{"home", []int{1, 3}},
+		{"work", []int{1}},
+		{"garden", []int{}},
+	} {
+		var list struct{ TodoList []todoJSON }
+		do(t, env, `query ($tag: String) { todoList(byTag: $tag) { Id } }`, map[string]interface{}{"tag": tc.tag}, &list)
+		if got := todoIds(list.TodoList); !equalInts(got, tc.want) {
+			t.Errorf("byTag %q = %v, want %v", tc.tag, got, tc.want)
+		}
+	}
+}
+
+func TestAddTagErrors(t *testing.T) {
+	env := newEnv(t)
+
+	for _, tc := range []struct {
+		query string
+		code  string
+	}{
+		{`mutation { addTag(todoId: 1, name: "  ") { Id } }`, graph.CodeValidation},
+		{`mutation { addTag(todoId: 99, name: "home") { Id } }`, graph.CodeNotFound},
+	} {
+		if code := errorCode(t, env.Do(tc.query, nil)); code != tc.code {
+			t.Errorf("%s: code = %q, want %q", tc.query, code, tc.code)
+		}
+	}
+}
+
+func TestSetTagColor(t *testing.T) {
+	env := newEnv(t)
+	for _, name := range []string{"home", "work"} {
+		do(t, env, `mutation ($name: String!) { addTag(todoId: 1, name: $name) { Id } }`,
+			map[string]interface{}{"name": name}, &struct{}{})
+	}
+	do(t, env, `mutation { addTag(todoId: 3, name: "home") { Id } }`, nil, &struct{}{})
+
+	var set struct {
+		SetTagColor struct{ Name, Color string }
+	}
+	do(t, env, `mutation { setTagColor(tag: "home", color: "#FF8800") { Name Color } }`, nil, &set)
+	if set.SetTagColor.Name != "home" || set.SetTagColor.Color != "#ff8800" {
+		t.Errorf("setTagColor = %+v, want home in #ff8800", set.SetTagColor)
+	}
+
+	var data struct {
+		A, B struct {
+			Tags []struct {
+				Name  string
+				Color *string
+			}
+		}
+	}
+	do(t, env, `{ a: todo(Id: 1) { Tags { Name Color } } b: todo(Id: 3) { Tags { Name Color } } }`, nil, &data)
+	if tags := data.B.Tags; len(tags) != 1 || tags[0].Color == nil || *tags[0].Color != "#ff8800" {
+		t.Errorf("todo 3 tags = %+v, want home in #ff8800", tags)
+	}
+	if tags := data.A.Tags; len(tags) != 2 || tags[0].Color == nil || tags[1].Color != nil {
+		t.Errorf("todo 1 tags = %+v, want home colored and work without a color", tags)
+	}
+}
+
+func TestSetTagColorErrors(t *testing.T) {
+	env := newEnv(t)
+	do(t, env, `mutation { addTag(todoId: 1, name: "home") { Id } }`, nil, &struct{}{})
+
+	for _, tc := range []struct {
+		tag, color string
+		code       string
+	}{
+		{"home", "orange", graph.CodeValidation},
+		{"home", "#ff880", graph.CodeValidation},
+		{"home", "ff8800", graph.CodeValidation},
+		{"home", "#gg8800", graph.CodeValidation},
+		{"garden", "#ff8800", graph.CodeNotFound},
+	} {
+		res := env.Do(`mutation ($tag: String!, $color: String!) { setTagColor(tag: $tag, color: $color) { Color } }`,
+			map[string]interface{}{"tag": tc.tag, "color": tc.color})
+		if code := errorCode(t, res); code != tc.code {
+			t.Errorf("setTagColor(%q, %q): code = %q, want %q", tc.tag, tc.color, code, tc.code)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+)
+
+func TestTransitionStatus(t *testing.T) {
+	env := newEnv(t)
+
+	var data struct {
+		TransitionStatus struct {
+			Status      string
+			Done        bool
+			Transitions []struct{ From, To string }
+		}
+	}
+	do(t, env, `mutation { transitionStatus(Id: 2, to: DONE) { Status Done Transitions { From To } } }`, nil, &data)
+
+	got := data.TransitionStatus
+	if got.Status != "DONE" || !got.Done {
+		t.Errorf("transitionStatus = %+v, want DONE", got)
+	}
+	if len(got.Transitions) != 1 || got.Transitions[0].From != "IN_PROGRESS" || got.Transitions[0].To != "DONE" {
+		t.Errorf("Transitions = %+v, want IN_PROGRESS -> DONE", got.Transitions)
+	}
+}
+
+func TestTransitionStatusNotAllowed(t *testing.T) {
+	env := newEnv(t)
+
+	res := env.Do(`mutation { transitionStatus(Id: 1, to: DONE) { Status } }`, nil)
+	if code := errorCode(t, res); code != graph.CodeConflict {
+		t.Errorf("code = %q, want %q", code, graph.CodeConflict)
+	}
+
+	var data struct{ Todo todoJSON }
+	do(t, env, `{ todo(Id: 1) { Status } }`, nil, &data)
+	if data.Todo.Status != "TODO" {
+		t.Errorf("status after a refused transition = %s, want TODO", data.Todo.Status)
+	}
+}
@@ -0,0 +1,410 @@
+package graph
+
+import (
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+var statusEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "TodoStatus",
+	Description: "Workflow state of a todo",
+	Values: graphql.EnumValueConfigMap{
+		"TODO": &graphql.EnumValueConfig{
+			Value: model.StatusTodo,
+		},
+		"IN_PROGRESS": &graphql.EnumValueConfig{
+			Value: model.StatusInProgress,
+		},
+		"DONE": &graphql.EnumValueConfig{
+			Value: model.StatusDone,
+		},
+	},
+})
+
+var priorityEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "Priority",
+	Description: "How urgent a todo is",
+	Values: graphql.EnumValueConfigMap{
+		"LOW": &graphql.EnumValueConfig{
+			Value: model.PriorityLow,
+		},
+		"MEDIUM": &graphql.EnumValueConfig{
+			Value: model.PriorityMedium,
+		},
+		"HIGH": &graphql.EnumValueConfig{
+			Value: model.PriorityHigh,
+		},
+	},
+})
+
+var sortFieldEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name:        "TodoSortField",
+	Description: "Todo attribute a list can be ordered by",
+	Values: graphql.EnumValueConfigMap{
+		"ID": &graphql.EnumValueConfig{
+			Value: store.SortByID,
+		},
+		"TEXT": &graphql.EnumValueConfig{
+			Value: store.SortByText,
+		},
+		"CREATED_AT": &graphql.EnumValueConfig{
+			Value: store.SortByCreated,
+		},
+		"PRIORITY": &graphql.EnumValueConfig{
+			Value:       store.SortByPriority,
+			Description: "LOW first when ascending, ties by Id",
+		},
+		"POSITION": &graphql.EnumValueConfig{
+			Value:       store.SortByPosition,
+			Description: "The manual order set by reorderTodo",
+		},
+	},
+})
+
+var sortOrderEnum = graphql.NewEnum(graphql.EnumConfig{
+	Name: "SortOrder",
+	Values: graphql.EnumValueConfigMap{
+		"ASC": &graphql.EnumValueConfig{
+			Value: store.Asc,
+		},
+		"DESC": &graphql.EnumValueConfig{
+			Value: store.Desc,
+		},
+	},
+})
+
+var pageInfoType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PageInfo",
+	Fields: graphql.Fields{
+		"hasNextPage": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.Boolean),
+		},
+		"endCursor": &graphql.Field{
+			Type: graphql.String,
+		},
+	},
+})
+
+var ageDistributionType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "AgeDistribution",
+	Description: "Counts of todos not done yet, by time since creation",
+	Fields: graphql.Fields{
+		"lessThanOneDay": &graphql.Field{
+			Type: graphql.Int,
+		},
+		"oneToSevenDays": &graphql.Field{
+			Type: graphql.Int,
+		},
+		"sevenToThirtyDays": &graphql.Field{
+			Type: graphql.Int,
+		},
+		"moreThanThirtyDays": &graphql.Field{
+			Type: graphql.Int,
+		},
+	},
+})
+
+var statusTransitionType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "StatusTransition",
+	Fields: graphql.Fields{
+		"From": &graphql.Field{
+			Type:        statusEnum,
+			Description: "Status before the change",
+		},
+		"To": &graphql.Field{
+			Type:        statusEnum,
+			Description: "Status after the change",
+		},
+		"At": &graphql.Field{
+			Type:        graphql.DateTime,
+			Description: "When the change was made",
+		},
+	},
+})
+
+var tagType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Tag",
+	Description: "Label shared by todos",
+	Fields: graphql.Fields{
+		"Id": &graphql.Field{
+			Type: graphql.Int,
+		},
+		"Name": &graphql.Field{
+			Type:        graphql.String,
+			Description: "Unique name of the tag",
+		},
+		"Color": &graphql.Field{
+			Type:        graphql.String,
+			Description: "Hex color such as #ff8800 set with setTagColor, null when there is none",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				if tag, ok := p.Source.(model.Tag); ok && tag.Color != "" {
+					return tag.Color, nil
+				}
+				return nil, nil
+			},
+		},
+	},
+})
+
+var settingType = graphql.NewObject(graphql.ObjectConfig{
+	Name:        "Setting",
+	Description: "One configuration value of the running server",
+	Fields: graphql.Fields{
+		"name": &graphql.Field{
+			Type: graphql.String,
+		},
+		"value": &graphql.Field{
+			Type: graphql.String,
+		},
+	},
+})
+
+// types holds the object types of a single schema. They are built per
+// schema so that field resolvers can close over its store.
+type types struct {
+	todo              *graphql.Object
+	board             *graphql.Object
+	todoConnection    *graphql.Object
+	criticalPath      *graphql.Object
+	updateTodoPayload *graphql.Object
+}
+
+func newTypes(s store.TodoStore) *types {
+	t := &types{}
+	t.todo = newTodoType(s)
+	t.board = newBoardType(t.todo)
+	t.todoConnection = newTodoConnectionType(t.todo)
+	t.criticalPath = newCriticalPathType(t.todo)
+	t.updateTodoPayload = newUpdateTodoPayloadType(t.todo)
+
+	return t
+}
+
+// define custom GraphQL ObjectType `todoType` for our Golang struct `Todo`
+// Note that
+// - the fields in our todoType maps with the field names in our struct
+// - the field type matches the field type in our struct
+func newTodoType(s store.TodoStore) *graphql.Object {
+	todo := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"Id": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Unique identifier of the todo",
+			},
+			"UserId": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Id of the user owning the todo, 0 for anonymous",
+			},
+			"Text": &graphql.Field{
+				Type:        graphql.String,
+				Description: "What needs to be done",
+			},
+			"Done": &graphql.Field{
+				Type:              graphql.Boolean,
+				Description:       "Whether the todo is done, same as Status == DONE",
+				DeprecationReason: "Use Status, which can also express IN_PROGRESS",
+			},
+			"Status": &graphql.Field{
+				Type:        statusEnum,
+				Description: "Workflow state of the todo",
+			},
+			"Created": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "When the todo was created",
+			},
+			"DueDate": &graphql.Field{
+				Type:        graphql.DateTime,
+				Description: "When the todo should be done by, null when it has no due date",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					todo, ok := sourceTodo(p.Source)
+					if !ok || todo.DueDate.IsZero() {
+						return nil, nil
+					}
+
+					return todo.DueDate, nil
+				},
+			},
+			"Estimate": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Estimated effort in points, 0 when not estimated",
+			},
+			"Priority": &graphql.Field{
+				Type:        priorityEnum,
+				Description: "How urgent the todo is",
+			},
+			"Position": &graphql.Field{
+				Type:        graphql.Float,
+				Description: "Place in the manual order set by reorderTodo, lower comes first",
+			},
+			"Overdue": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Whether the due date has passed while the todo is not done",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					todo, ok := sourceTodo(p.Source)
+					if !ok {
+						return false, nil
+					}
+
+					return todo.Overdue(time.Now()), nil
+				},
+			},
+			"Tags": &graphql.Field{
+				Type:        graphql.NewList(tagType),
+				Description: "Tags attached with addTag, by name",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					todo, ok := sourceTodo(p.Source)
+					if !ok {
+						return nil, nil
+					}
+
+					tags, err := loaderFrom(p.Context).tagsOf(p.Context, s, todo.Id)
+					if tags == nil && err == nil {
+						tags = []model.Tag{}
+					}
+					return tags, err
+				},
+			},
+			"Transitions": &graphql.Field{
+				Type:        graphql.NewList(statusTransitionType),
+				Description: "Status changes made through transitionStatus, oldest first",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					todo, ok := sourceTodo(p.Source)
+					if !ok {
+						return nil, nil
+					}
+
+					return s.Transitions(p.Context, todo.Id)
+				},
+			},
+		},
+	})
+
+	// the dependency fields refer back to Todo, so they are added once it
+	// exists
+	todo.AddFieldConfig("blockedBy", &graphql.Field{
+		Type:        graphql.NewList(todo),
+		Description: "Todos this todo depends on",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			source, ok := sourceTodo(p.Source)
+			if !ok {
+				return nil, nil
+			}
+
+			return relatedTodos(p.Context, s, source, false)
+		},
+	})
+	todo.AddFieldConfig("blocks", &graphql.Field{
+		Type:        graphql.NewList(todo),
+		Description: "Todos depending on this todo",
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			source, ok := sourceTodo(p.Source)
+			if !ok {
+				return nil, nil
+			}
+
+			return relatedTodos(p.Context, s, source, true)
+		},
+	})
+
+	return todo
+}
+
+// sourceTodo returns the todo a Todo field is resolved on. List resolvers
+// hand out values while single lookups hand out pointers.
+func sourceTodo(source interface{}) (*model.Todo, bool) {
+	switch todo := source.(type) {
+	case *model.Todo:
+		return todo, todo != nil
+	case model.Todo:
+		return &todo, true
+	}
+	return nil, false
+}
+
+// boardType groups todos into kanban columns. It resolves from a *board.
+func newBoardType(todoType *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "Board",
+		Fields: graphql.Fields{
+			"todo": &graphql.Field{
+				Type: graphql.NewList(todoType),
+			},
+			"inProgress": &graphql.Field{
+				Type: graphql.NewList(todoType),
+			},
+			"done": &graphql.Field{
+				Type: graphql.NewList(todoType),
+			},
+		},
+	})
+}
+
+// criticalPathType is a chain of dependent todos. It resolves from a
+// *criticalPath.
+func newCriticalPathType(todoType *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name:        "CriticalPath",
+		Description: "Longest chain of open todos that depend on each other",
+		Fields: graphql.Fields{
+			"todos": &graphql.Field{
+				Type:        graphql.NewList(todoType),
+				Description: "The chain in the order it has to be worked on",
+			},
+			"totalEstimate": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "Sum of the estimates along the chain",
+			},
+		},
+	})
+}
+
+// updateTodoPayloadType is the result of updateTodo. It resolves from an
+// *updateTodoPayload.
+func newUpdateTodoPayloadType(todoType *graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "UpdateTodoPayload",
+		Fields: graphql.Fields{
+			"todo": &graphql.Field{
+				Type:        todoType,
+				Description: "The todo after the update, null when no todo has the Id",
+			},
+			"affectedRows": &graphql.Field{
+				Type:        graphql.Int,
+				Description: "1 when the todo changed, 0 when nothing matched or nothing was different",
+			},
+		},
+	})
+}
+
+// todoConnectionType is a Relay-style page of todos. It resolves from a
+// *todoConnection.
+func newTodoConnectionType(todoType *graphql.Object) *graphql.Object {
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+			},
+			"node": &graphql.Field{
+				Type: todoType,
+			},
+		},
+	})
+
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: "TodoConnection",
+		Fields: graphql.Fields{
+			"edges": &graphql.Field{
+				Type: graphql.NewList(edgeType),
+			},
+			"pageInfo": &graphql.Field{
+				Type: graphql.NewNonNull(pageInfoType),
+			},
+		},
+	})
+}
@@ -0,0 +1,42 @@
+package graph
+
+import (
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// updateTodoPayload is the source value for UpdateTodoPayload.
+type updateTodoPayload struct {
+	Todo         *model.Todo
+	AffectedRows int
+}
+
+// sameContent reports whether updateTodo left every field it can change
+// as it was.
+func sameContent(a, b model.Todo) bool {
+	return a.Text == b.Text &&
+		a.Status == b.Status &&
+		a.Done == b.Done &&
+		a.DueDate.Equal(b.DueDate) &&
+		a.Estimate == b.Estimate &&
+		a.Priority == b.Priority
+}
+
+// columns collects the columns an update writes, each once.
+type columns []string
+
+func (c *columns) add(names ...string) {
+	for _, name := range names {
+		if !c.has(name) {
+			*c = append(*c, name)
+		}
+	}
+}
+
+func (c columns) has(name string) bool {
+	for _, col := range c {
+		if col == name {
+			return true
+		}
+	}
+	return false
+}
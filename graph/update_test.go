@@ -0,0 +1,64 @@
+package graph_test
+
+import "testing"
+
+// updatePayloadJSON is an UpdateTodoPayload as the schema returns it.
+type updatePayloadJSON struct {
+	AffectedRows int
+	Todo         *todoJSON
+}
+
+func TestUpdateTodoAffectedRows(t *testing.T) {
+	env := newEnv(t)
+
+	for _, tc := range []struct {
+		query string
+		rows  int
+	}{
+		{`mutation { updateTodo(Id: 1, Text: "write the summary") { affectedRows todo { Id } } }`, 1},
+		{`mutation { updateTodo(Id: 1, Text: "write the summary") { affectedRows todo { Id } } }`, 0},
+		{`mutation { updateTodo(Id: 2, Status: IN_PROGRESS) { affectedRows todo { Id } } }`, 0},
+		{`mutation { updateTodo(Id: 3, Done: true) { affectedRows todo { Id } } }`, 0},
+		{`mutation { updateTodo(Id: 3, Priority: MEDIUM) { affectedRows todo { Id } } }`, 0},
+		{`mutation { updateTodo(Id: 3, Priority: HIGH) { affectedRows todo { Id } } }`, 1},
+		{`mutation { updateTodo(Id: 2) { affectedRows todo { Id } } }`, 0},
+	} {
+		var data struct{ UpdateTodo updatePayloadJSON }
+		do(t, env, tc.query, nil, &data)
+		if data.UpdateTodo.AffectedRows != tc.rows {
+			t.Errorf("%s: affectedRows = %d, want %d", tc.query, data.UpdateTodo.AffectedRows, tc.rows)
+		}
+		// the todo comes back whether it changed or not
+		if data.UpdateTodo.Todo == nil {
+			t.Errorf("%s: todo is null", tc.query)
+		}
+	}
+}
+
+func TestUpdateTodoPartial(t *testing.T) {
+	env := newEnv(t)
+
+	var data struct{ UpdateTodo updatePayloadJSON }
+	do(t, env, `mutation { updateTodo(Id: 1, Done: true) { todo { Text Done Status } } }`, nil, &data)
+	if got := data.UpdateTodo.Todo; got.Text != "write the report" || !got.Done || got.Status != "DONE" {
+		t.Errorf("sending only Done = %+v, want the text kept", got)
+	}
+
+	do(t, env, `mutation { updateTodo(Id: 2, Text: "merge the pull request") { todo { Text Done Status } } }`, nil, &data)
+	if got := data.UpdateTodo.Todo; got.Text != "merge the pull request" || got.Done || got.Status != "IN_PROGRESS" {
+		t.Errorf("sending only Text = %+v, want the status kept", got)
+	}
+
+	// and what was kept is what got stored
+	var list struct{ TodoList []todoJSON }
+	do(t, env, `{ todoList { Id Text Done Status } }`, nil, &list)
+	want := []todoJSON{
+		{Id: 1, Text: "write the report", Done: true, Status: "DONE"},
+		{Id: 2, Text: "merge the pull request", Status: "IN_PROGRESS"},
+	}
+	for i, todo := range want {
+		if list.TodoList[i] != todo {
+			t.Errorf("stored todo %d = %+v, want %+v", todo.Id, list.TodoList[i], todo)
+		}
+	}
+}
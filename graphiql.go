@@ -0,0 +1,16 @@
+//go:build !nographiql
+// +build !nographiql
+
+package main
+
+import (
+	"net/http"
+
+	"github.com/mnmtanish/go-graphiql"
+)
+
+// graphiqlHandler serves the GraphiQL web UI. Build with the nographiql
+// tag to leave it, and the go-graphiql dependency, out.
+func graphiqlHandler() http.Handler {
+	return http.HandlerFunc(graphiql.ServeGraphiQL)
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// minGzipSize is the smallest response worth compressing; below it gzip's
+// framing and the CPU time cost more than they save.
+const minGzipSize = 1024
+
+// withGzip compresses responses of next for clients that accept gzip. The
+// response is buffered so that small ones, and errors written by any
+// handler down the chain, go out as they are with their status intact.
+func withGzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		buf := &bufferedResponse{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(buf, r)
+
+		if buf.body.Len() < minGzipSize || w.Header().Get("Content-Encoding") != "" {
+			w.WriteHeader(buf.status)
+			w.Write(buf.body.Bytes())
+			return
+		}
+
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Header().Del("Content-Length")
+		w.WriteHeader(buf.status)
+		gz := gzip.NewWriter(w)
+		gz.Write(buf.body.Bytes())
+		gz.Close()
+	})
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows gzip.
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		coding := strings.TrimSpace(params[0])
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+
+		allowed := true
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				allowed = err == nil && q > 0
+			}
+		}
+		if allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedResponse holds the status and body written by a handler until
+// withGzip decides how to send them.
+type bufferedResponse struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if !b.wroteHeader {
+		b.status = status
+		b.wroteHeader = true
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
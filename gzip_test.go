@@ -0,0 +1,69 @@
+package main
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGzip(t *testing.T) {
+	large := strings.Repeat("todo ", minGzipSize)
+	h := withGzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("size") == "small" {
+			w.Write([]byte("{}"))
+			return
+		}
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte(large))
+	}))
+
+	for _, tc := range []struct {
+		name, accept, size string
+		gzipped            bool
+	}{
+		{"large, gzip accepted", "gzip, deflate", "large", true},
+		{"large, any accepted", "*", "large", true},
+		{"large, no Accept-Encoding", "", "large", false},
+		{"large, gzip refused", "gzip;q=0, deflate", "large", false},
+		{"small, gzip accepted", "gzip", "small", false},
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/graphql?size="+tc.size, nil)
+		if tc.accept != "" {
+			r.Header.Set("Accept-Encoding", tc.accept)
+		}
+		w := serve(h, r)
+
+		if vary := w.Header().Get("Vary"); vary != "Accept-Encoding" {
+			t.Errorf("%s: Vary = %q, want Accept-Encoding", tc.name, vary)
+		}
+		if gzipped := w.Header().Get("Content-Encoding") == "gzip"; gzipped != tc.gzipped {
+			t.Errorf("%s: gzipped = %v, want %v", tc.name, gzipped, tc.gzipped)
+			continue
+		}
+
+		body := w.Body.String()
+		if tc.gzipped {
+			zr, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			b, err := ioutil.ReadAll(zr)
+			if err != nil {
+				t.Fatalf("%s: %v", tc.name, err)
+			}
+			body = string(b)
+		}
+
+		want, status := large, http.StatusTeapot
+		if tc.size == "small" {
+			want, status = "{}", http.StatusOK
+		}
+		if body != want || w.Code != status {
+			t.Errorf("%s: status %d and %d bytes, want %d and %d bytes", tc.name, w.Code, len(body), status, len(want))
+		}
+	}
+}
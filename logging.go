@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+)
+
+// requestLog is the single structured line written for each request.
+type requestLog struct {
+	Method     string  `json:"method"`
+	Path       string  `json:"path"`
+	Status     int     `json:"status"`
+	DurationMs float64 `json:"duration_ms"`
+	Errors     bool    `json:"errors"`
+	Query      string  `json:"query,omitempty"`
+
+	logQuery bool
+}
+
+// record notes the outcome of the GraphQL execution. The query text is only
+// kept when query logging was enabled since it may contain user data.
+func (l *requestLog) record(query string, hasErrors bool) {
+	l.Errors = hasErrors
+	if l.logQuery {
+		l.Query = query
+	}
+}
+
+type requestLogKey struct{}
+
+// requestLogFrom returns the log entry for the current request, or nil when
+// the handler is not wrapped by withRequestLog.
+func requestLogFrom(ctx context.Context) *requestLog {
+	l, _ := ctx.Value(requestLogKey{}).(*requestLog)
+	return l
+}
+
+// statusRecorder remembers the status code written by the wrapped handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// withRequestLog writes one JSON line per request to logger once next has
+// finished.
+func withRequestLog(next http.Handler, logger *log.Logger, logQuery bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		entry := &requestLog{
+			Method:   r.Method,
+			Path:     r.URL.Path,
+			logQuery: logQuery,
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), requestLogKey{}, entry)))
+
+		entry.Status = rec.status
+		entry.DurationMs = float64(time.Since(start)) / float64(time.Millisecond)
+		line, err := json.Marshal(entry)
+		if err != nil {
+			logger.Println(err)
+			return
+		}
+		logger.Println(string(line))
+	})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestLog(t *testing.T) {
+	for _, logQuery := range []bool{false, true} {
+		var buf bytes.Buffer
+		h := withRequestLog(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestLogFrom(r.Context()).record("{ todoList { Id } }", true)
+			w.WriteHeader(http.StatusTeapot)
+		}), log.New(&buf, "", 0), logQuery)
+
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/graphql", nil))
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 1 {
+			t.Fatalf("logged %q, want one line", buf.String())
+		}
+		var entry requestLog
+		if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+			t.Fatalf("log line %q is not JSON: %v", lines[0], err)
+		}
+
+		want := requestLog{Method: http.MethodPost, Path: "/graphql", Status: http.StatusTeapot, Errors: true}
+		if logQuery {
+			want.Query = "{ todoList { Id } }"
+		}
+		entry.DurationMs = 0
+		if entry != want {
+			t.Errorf("logQuery=%v: logged %+v, want %+v", logQuery, entry, want)
+		}
+	}
+}
@@ -1,191 +1,72 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
 	"os"
+	"time"
 
-	"github.com/go-xorm/xorm"
 	"github.com/graphql-go/graphql"
-	"github.com/mnmtanish/go-graphiql"
+	"github.com/graphql-go/graphql/gqlerrors"
 
 	_ "github.com/mattn/go-sqlite3"
-)
-
-var engine *xorm.Engine
 
-type Todo struct {
-	Id      int `xorm:"pk autoincr" `
-	Text    string
-	Done    bool
-	Version int `xorm:"version"` // Optimistic Locking
-}
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/metrics"
+	"github.com/nevzatalkan/golang-graphql-todo-example/pubsub"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
 
-// define custom GraphQL ObjectType `todoType` for our Golang struct `Todo`
-// Note that
-// - the fields in our todoType maps with the json tags for the fields in our struct
-// - the field type matches the field type in our struct
-var todoType = graphql.NewObject(graphql.ObjectConfig{
-	Name: "Todo",
-	Fields: graphql.Fields{
-		"Id": &graphql.Field{
-			Type: graphql.Int,
-		},
-		"Text": &graphql.Field{
-			Type: graphql.String,
-		},
-		"Done": &graphql.Field{
-			Type: graphql.Boolean,
-		},
-	},
-})
-
-// root mutation
-var rootMutation = graphql.NewObject(graphql.ObjectConfig{
-	Name: "RootMutation",
-	Fields: graphql.Fields{
-		/*
-			curl -g 'http://localhost:8081/graphql?query=mutation+_{createTodo(Text:"My+new+todo"){Id,Text,Done}}'
-		*/
-		"createTodo": &graphql.Field{
-			Type:        todoType, // the return type for this field
-			Description: "Create new todo",
-			Args: graphql.FieldConfigArgument{
-				"Text": &graphql.ArgumentConfig{
-					Type: graphql.NewNonNull(graphql.String),
-				},
-			},
-			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
-
-				Text, _ := params.Args["Text"].(string)
-
-				newTodo := Todo{
-					Text: Text,
-					Done: false,
-				}
-
-				return newTodo, nil
-			},
-		},
-		/*
-			curl -g 'http://localhost:8081/graphql?query=mutation+_{updateTodo(Id:"a",Done:true){Id,Text,Done}}'
-		*/
-		"updateTodo": &graphql.Field{
-			Type:        todoType, // the return type for this field
-			Description: "Update existing todo, mark it Done or not Done",
-			Args: graphql.FieldConfigArgument{
-				"Done": &graphql.ArgumentConfig{
-					Type: graphql.Boolean,
-				},
-				"Id": &graphql.ArgumentConfig{
-					Type: graphql.NewNonNull(graphql.Int),
-				},
-			},
-			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
-				// marshall and cast the argument value
-				DoneParam, _ := params.Args["Done"].(bool)
-				IdParam, _ := params.Args["Id"].(int)
-
-				// todo := Todo{Id: IdParam, Done: DoneParam}
-
-				engine, _ = xorm.NewEngine("sqlite3", "./test.db")
-
-				todo := &Todo{Id: IdParam}
-				engine.Id(IdParam).Get(todo)
-
-				todo.Done = DoneParam
-				engine.Cols("Done").Update(todo)
-
-				engine.Id(IdParam).Get(todo)
-
-				return todo, nil
-			},
-		},
-	},
-})
-
-// root query
-// we just define a trivial example here, since root query is required.
-// Test with curl
-// curl -g 'http://localhost:8081/graphql?query={lastTodo{Id,Text,Done}}'
-var rootQuery = graphql.NewObject(graphql.ObjectConfig{
-	Name: "RootQuery",
-	Fields: graphql.Fields{
-
-		/*
-		   curl -g 'http://localhost:8081/graphql?query={todo(Id:"b"){Id,Text,Done}}'
-		*/
-		"todo": &graphql.Field{
-			Type:        todoType,
-			Description: "Get single todo",
-			Args: graphql.FieldConfigArgument{
-				"Id": &graphql.ArgumentConfig{
-					Type: graphql.Int,
-				},
-			},
-			Resolve: func(params graphql.ResolveParams) (interface{}, error) {
-
-				idQuery, isOK := params.Args["Id"].(int)
-				if isOK {
-
-					engine, _ = xorm.NewEngine("sqlite3", "./test.db")
-					err := engine.Sync(new(Todo))
-
-					var todo = Todo{Id: idQuery}
-					_, err = engine.Id(idQuery).Get(&todo)
-					return todo, err
-				}
-
-				return Todo{}, nil
-			},
-		},
-
-		/*
-		   curl -g 'http://localhost:8081/graphql?query={todoList{Id,Text,Done}}'
-		*/
-		"todoList": &graphql.Field{
-			Type:        graphql.NewList(todoType),
-			Description: "List of todos",
-			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
-
-				var all []Todo
-
-				engine, _ = xorm.NewEngine("sqlite3", "./test.db")
-
-				_ = engine.Find(&all)
-				fmt.Println("%v", all)
-				return all, nil
-			},
-		},
-	},
-})
-
-// define schema, with our rootQuery and rootMutation
-var schema, _ = graphql.NewSchema(graphql.SchemaConfig{
-	Query:    rootQuery,
-	Mutation: rootMutation,
-})
-
-func serveGraphQL(s graphql.Schema) http.HandlerFunc {
+// serveGraphQL executes the posted query against s. Each request gets a
+// context derived from the client's that is cancelled after timeout.
+func serveGraphQL(s graphql.Schema, timeout time.Duration) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		sendError := func(err error) {
 			w.WriteHeader(500)
 			w.Write([]byte(err.Error()))
 		}
 
-		req := &graphiql.Request{}
-		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+		req, err := readRequest(r)
+		switch {
+		case err == errUnsupportedMediaType:
+			writeGraphQLError(w, http.StatusUnsupportedMediaType, err.Error())
+			return
+		case isBodyTooLarge(err):
+			writeGraphQLError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		case err != nil:
 			sendError(err)
 			return
 		}
 
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
 		res := graphql.Do(graphql.Params{
-			Schema:        s,
-			RequestString: req.Query,
+			Schema:         s,
+			RequestString:  req.Query,
+			VariableValues: req.Variables,
+			OperationName:  req.OperationName,
+			Context:        graph.WithLoader(ctx),
 		})
+		if ctx.Err() == context.DeadlineExceeded {
+			timedOut := gqlerrors.NewFormattedError(fmt.Sprintf("request timed out after %s", timeout))
+			timedOut.Extensions = map[string]interface{}{"code": graph.CodeServiceUnavailable}
+			res.Errors = append(res.Errors, timedOut)
+		}
+		addErrorCodes(res.Errors)
+		if entry := requestLogFrom(r.Context()); entry != nil {
+			entry.record(req.Query, res.HasErrors())
+		}
 
-		if err := json.NewEncoder(w).Encode(res); err != nil {
+		enc := json.NewEncoder(w)
+		if wantsPretty(r) {
+			enc.SetIndent("", "  ")
+		}
+		if err := enc.Encode(res); err != nil {
 			sendError(err)
 		}
 	}
@@ -204,37 +85,133 @@ func deleteDb() {
 
 func main() {
 
-	deleteDb()
-
-	engine, _ = xorm.NewEngine("sqlite3", "./test.db")
-
-	// engine, err := xorm.NewEngine("sqlite3", "./test.db")
+	cfg, err := loadConfig()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	// if err != nil {
-	// 	fmt.Println(err)
-	// 	return
-	// }
+	// the bundled demo database starts fresh on every run
+	if cfg.DatabaseDriver == defaultDatabaseDriver && cfg.DatabaseDSN == defaultDatabaseDSN {
+		deleteDb()
+	}
 
-	engine.Sync2(new(Todo))
+	todoStore, err := store.Open(cfg.DatabaseDriver, cfg.DatabaseDSN)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer todoStore.Close()
+	todoStore.SetRetry(cfg.DatabaseRetry)
+	logger := log.New(os.Stderr, "", 0)
+	if cfg.LogSQL {
+		todoStore.LogSQL(logger)
+	}
 
-	engine.Insert(&Todo{Id: 1, Text: "sdfsdf", Done: false})
+	if cfg.UniqueText {
+		if err := todoStore.RequireUniqueText(); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
 
-	// todo := &Todo2{}
-	// engine.Id(1).Get(todo)
+	if cfg.Seed {
+		if err := seed(context.Background(), todoStore); err != nil {
+			fmt.Println(err)
+			return
+		}
+	}
 
-	// todo.Done = true
-	// engine.Cols("Done").Update(todo)
+	broker := pubsub.NewBroker()
+	registry := metrics.NewRegistry()
+	opts := []graph.Option{
+		graph.WithBroker(broker),
+		graph.WithMetrics(registry),
+		graph.WithSettings(cfg.settings()),
+	}
+	if cfg.Transitions != nil {
+		opts = append(opts, graph.WithTransitions(cfg.Transitions))
+	}
 
-	// engine.Id(1).Get(todo)
+	schema, err := graph.NewSchema(todoStore, opts...)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	schemaJSON, err := serveSchemaJSON(schema)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 
-	http.HandleFunc("/", graphiql.ServeGraphiQL)
-	http.HandleFunc("/graphql", serveGraphQL(schema))
+	var ui http.Handler
+	if cfg.GraphiQL {
+		ui = graphiqlHandler()
+	}
+	http.Handle("/", withRequestLog(serveRoot(ui), logger, false))
+	var limiter *rateLimiter
+	if cfg.RateLimit > 0 {
+		limiter = newRateLimiter(cfg.RateLimit, cfg.RateLimitBurst, cfg.TrustProxy)
+	}
+	var cache *queryCache
+	if cfg.QueryCacheTTL > 0 {
+		cache = newQueryCache(cfg.QueryCacheTTL)
+	}
+	var conns *connLimiter
+	if cfg.MaxSubscriptions > 0 || cfg.MaxSubscriptionsPerIP > 0 {
+		conns = newConnLimiter(cfg.MaxSubscriptions, cfg.MaxSubscriptionsPerIP, cfg.TrustProxy)
+	}
+	var graphqlHandler http.Handler = serveGraphQL(schema, cfg.RequestTimeout)
+	graphqlHandler = withQueryCache(graphqlHandler, cache)
+	graphqlHandler = withDepthLimit(graphqlHandler, cfg.MaxQueryDepth)
+	graphqlHandler = withUser(graphqlHandler)
+	graphqlHandler = withAdminKey(graphqlHandler, cfg.AdminKey)
+	graphqlHandler = withAPIKey(graphqlHandler, cfg.APIKey)
+	graphqlHandler = withPersistedQueries(graphqlHandler, newMemoryQueryStore())
+	graphqlHandler = withBodyLimit(graphqlHandler, cfg.MaxBodyBytes)
+	graphqlHandler = withRateLimit(graphqlHandler, limiter)
+	graphqlHandler = withRecover(graphqlHandler)
+	graphqlHandler = withGzip(graphqlHandler)
+	graphqlHandler = withMethods(graphqlHandler, http.MethodGet, http.MethodPost)
+	graphqlHandler = withRequestLog(graphqlHandler, logger, cfg.LogQueries)
+	http.Handle("/graphql", graphqlHandler)
+	var validateHandler http.Handler = serveValidate(schema)
+	validateHandler = withBodyLimit(validateHandler, cfg.MaxBodyBytes)
+	validateHandler = withRateLimit(validateHandler, limiter)
+	validateHandler = withRecover(validateHandler)
+	validateHandler = withMethods(validateHandler, http.MethodGet, http.MethodPost)
+	validateHandler = withRequestLog(validateHandler, logger, cfg.LogQueries)
+	http.Handle("/validate", validateHandler)
+	http.Handle("/schema.graphql", withMethods(withRecover(serveSchema(schema)), http.MethodGet, http.MethodHead))
+	http.Handle("/schema.json", withMethods(withRecover(schemaJSON), http.MethodGet, http.MethodHead))
+	var subscriptionsHandler http.Handler = serveSubscriptions(schema, broker, cfg.MaxQueryDepth, conns)
+	subscriptionsHandler = withDepthLimit(subscriptionsHandler, cfg.MaxQueryDepth)
+	subscriptionsHandler = withUser(subscriptionsHandler)
+	subscriptionsHandler = withAPIKey(subscriptionsHandler, cfg.APIKey)
+	subscriptionsHandler = withRateLimit(subscriptionsHandler, limiter)
+	subscriptionsHandler = withRecover(subscriptionsHandler)
+	subscriptionsHandler = withMethods(subscriptionsHandler, http.MethodGet)
+	http.Handle("/subscriptions", subscriptionsHandler)
+
+	var restHandler http.Handler = serveREST(todoStore, broker)
+	restHandler = withCacheClear(restHandler, cache)
+	restHandler = withUser(restHandler)
+	restHandler = withWriteAPIKey(restHandler, cfg.APIKey)
+	restHandler = withBodyLimit(restHandler, cfg.MaxBodyBytes)
+	restHandler = withRateLimit(restHandler, limiter)
+	restHandler = withRecover(restHandler)
+	restHandler = withGzip(restHandler)
+	restHandler = withRequestLog(restHandler, logger, false)
+	http.Handle(restPrefix, restHandler)
+	http.Handle(restPrefix+"/", restHandler)
+	http.Handle("/metrics", withMethods(registry, http.MethodGet, http.MethodHead))
+	http.Handle("/export", withRequestLog(withRecover(withUser(serveExport(todoStore))), logger, false))
 
 	fmt.Println("Now server is running on port 8081")
-	fmt.Println("Get single todo: curl -g 'http://localhost:8081/graphql?query={todo(id:\"b\"){id,text,done}}'")
-	fmt.Println("Create new todo: curl -g 'http://localhost:8081/graphql?query=mutation+_{createTodo(text:\"My+new+todo\"){id,text,done}}'")
-	fmt.Println("Update todo: curl -g 'http://localhost:8081/graphql?query=mutation+_{updateTodo(id:\"a\",done:true){id,text,done}}'")
-	fmt.Println("Load todo list: curl -g 'http://localhost:8081/graphql?query={todoList{id,text,done}}'")
+	fmt.Println("Get single todo: curl -g 'http://localhost:8081/graphql?query={todo(Id:1){Id,Text,Done}}'")
+	fmt.Println("Create new todo: curl -g 'http://localhost:8081/graphql?query=mutation+_{createTodo(Text:\"My+new+todo\"){Id,Text,Done}}'")
+	fmt.Println("Update todo: curl -g 'http://localhost:8081/graphql?query=mutation+_{updateTodo(Id:1,Done:true){affectedRows,todo{Id,Text,Done}}}'")
+	fmt.Println("Load todo list: curl -g 'http://localhost:8081/graphql?query={todoList{Id,Text,Done}}'")
 	fmt.Println("Access the web app via browser at 'http://localhost:8081'")
 
 	http.ListenAndServe(":8081", nil)
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// graphQLResponse is the body /graphql answers with.
+type graphQLResponse struct {
+	Data   json.RawMessage
+	Errors []struct {
+		Message    string
+		Extensions map[string]interface{}
+	}
+}
+
+// newSchema builds the schema over s, failing the test on error.
+func newSchema(t *testing.T, s store.TodoStore) graphql.Schema {
+	t.Helper()
+
+	schema, err := graph.NewSchema(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+// newQueryPost returns a JSON POST of query to /graphql.
+func newQueryPost(query string) *http.Request {
+	body, _ := json.Marshal(graphQLRequest{Query: query})
+	r := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	return r
+}
+
+// serve records the response of h to r.
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// postQuery sends query to h as a JSON POST to /graphql.
+func postQuery(h http.Handler, query string) *httptest.ResponseRecorder {
+	return serve(h, newQueryPost(query))
+}
+
+// decodeResponse reads the GraphQL response recorded in w.
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder) graphQLResponse {
+	t.Helper()
+
+	var res graphQLResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+	return res
+}
+
+// slowStore blocks every List until the request is cancelled.
+type slowStore struct {
+	store.TodoStore
+}
+
+func (slowStore) List(ctx context.Context, sort store.Sort) ([]model.Todo, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestServeGraphQLTimeout(t *testing.T) {
+	h := serveGraphQL(newSchema(t, slowStore{store.NewMemoryStore()}), 20*time.Millisecond)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postQuery(h, `{ todoList { Id } }`) }()
+
+	var w *httptest.ResponseRecorder
+	select {
+	case w = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request still running long after the timeout")
+	}
+
+	res := decodeResponse(t, w)
+	timedOut := false
+	for _, e := range res.Errors {
+		if e.Extensions["code"] == graph.CodeServiceUnavailable {
+			timedOut = true
+		}
+	}
+	if !timedOut {
+		t.Errorf("errors = %+v, want one coded %s", res.Errors, graph.CodeServiceUnavailable)
+	}
+}
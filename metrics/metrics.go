@@ -0,0 +1,122 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Buckets are the upper bounds, in seconds, of the latency histogram.
+var Buckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 5}
+
+// fieldStats aggregates the calls of one field.
+type fieldStats struct {
+	calls   uint64
+	errors  uint64
+	sum     float64
+	buckets []uint64 // cumulative, one per Buckets entry
+}
+
+// Registry collects per-field resolver calls, errors and latencies and
+// serves them in the Prometheus text format. It is safe for concurrent
+// use.
+type Registry struct {
+	mu     sync.Mutex
+	fields map[string]*fieldStats
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{fields: make(map[string]*fieldStats)}
+}
+
+// Observe records one resolution of field that took d and failed when err
+// is not nil.
+func (r *Registry) Observe(field string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats, ok := r.fields[field]
+	if !ok {
+		stats = &fieldStats{buckets: make([]uint64, len(Buckets))}
+		r.fields[field] = stats
+	}
+
+	seconds := d.Seconds()
+	stats.calls++
+	if err != nil {
+		stats.errors++
+	}
+	stats.sum += seconds
+	for i, bound := range Buckets {
+		if seconds <= bound {
+			stats.buckets[i]++
+		}
+	}
+}
+
+// ServeHTTP writes the metrics, for mounting at /metrics.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text format, fields in
+// name order.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	names := make([]string, 0, len(r.fields))
+	for name := range r.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	cw := &countingWriter{w: w}
+	fmt.Fprintln(cw, "# HELP graphql_field_calls_total Resolver calls by field.")
+	fmt.Fprintln(cw, "# TYPE graphql_field_calls_total counter")
+	for _, name := range names {
+		fmt.Fprintf(cw, "graphql_field_calls_total{field=%q} %d\n", name, r.fields[name].calls)
+	}
+
+	fmt.Fprintln(cw, "# HELP graphql_field_errors_total Resolver calls that returned an error, by field.")
+	fmt.Fprintln(cw, "# TYPE graphql_field_errors_total counter")
+	for _, name := range names {
+		fmt.Fprintf(cw, "graphql_field_errors_total{field=%q} %d\n", name, r.fields[name].errors)
+	}
+
+	fmt.Fprintln(cw, "# HELP graphql_field_duration_seconds Resolver latency by field.")
+	fmt.Fprintln(cw, "# TYPE graphql_field_duration_seconds histogram")
+	for _, name := range names {
+		stats := r.fields[name]
+		for i, bound := range Buckets {
+			fmt.Fprintf(cw, "graphql_field_duration_seconds_bucket{field=%q,le=\"%g\"} %d\n", name, bound, stats.buckets[i])
+		}
+		fmt.Fprintf(cw, "graphql_field_duration_seconds_bucket{field=%q,le=\"+Inf\"} %d\n", name, stats.calls)
+		fmt.Fprintf(cw, "graphql_field_duration_seconds_sum{field=%q} %g\n", name, stats.sum)
+		fmt.Fprintf(cw, "graphql_field_duration_seconds_count{field=%q} %d\n", name, stats.calls)
+	}
+
+	return cw.n, cw.err
+}
+
+// countingWriter remembers how much was written and the first error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,40 @@
+package model
+
+// Dependency records that a todo cannot start before another one is done.
+type Dependency struct {
+	Id        int64 `xorm:"pk autoincr"`
+	TodoId    int   `xorm:"index"`
+	DependsOn int   `xorm:"index"`
+}
+
+// DependencyGraph maps a todo Id to the Ids of the todos it depends on.
+type DependencyGraph map[int][]int
+
+// NewDependencyGraph builds the graph of the given dependencies.
+func NewDependencyGraph(deps []Dependency) DependencyGraph {
+	g := DependencyGraph{}
+	for _, dep := range deps {
+		g[dep.TodoId] = append(g[dep.TodoId], dep.DependsOn)
+	}
+	return g
+}
+
+// Reaches reports whether from depends on to, directly or through other
+// todos. Every todo reaches itself.
+func (g DependencyGraph) Reaches(from, to int) bool {
+	seen := map[int]bool{}
+	next := []int{from}
+	for len(next) > 0 {
+		id := next[len(next)-1]
+		next = next[:len(next)-1]
+		if id == to {
+			return true
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		next = append(next, g[id]...)
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package model
+
+// Priority is how urgent a todo is. It is stored as a number so that
+// ordering by it puts LOW before MEDIUM before HIGH.
+type Priority int
+
+const (
+	PriorityLow    Priority = 1
+	PriorityMedium Priority = 2
+	PriorityHigh   Priority = 3
+)
+
+// Valid reports whether p is one of the known priorities.
+func (p Priority) Valid() bool {
+	return p >= PriorityLow && p <= PriorityHigh
+}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "LOW"
+	case PriorityMedium:
+		return "MEDIUM"
+	case PriorityHigh:
+		return "HIGH"
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package model
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Status is the workflow state of a todo.
+type Status string
+
+const (
+	StatusTodo       Status = "TODO"
+	StatusInProgress Status = "IN_PROGRESS"
+	StatusDone       Status = "DONE"
+)
+
+// Valid reports whether s is one of the known statuses.
+func (s Status) Valid() bool {
+	switch s {
+	case StatusTodo, StatusInProgress, StatusDone:
+		return true
+	}
+	return false
+}
+
+// StatusTransition records a todo moving from one status to another.
+type StatusTransition struct {
+	Id     int64  `xorm:"pk autoincr"`
+	TodoId int    `xorm:"index"`
+	From   Status `xorm:"'from_status' varchar(16)"`
+	To     Status `xorm:"'to_status' varchar(16)"`
+	At     time.Time
+}
+
+// Transitions is a state machine listing, for each status, the statuses a
+// todo may move to next.
+type Transitions map[Status][]Status
+
+// DefaultTransitions allows TODO -> IN_PROGRESS -> DONE and each step back.
+func DefaultTransitions() Transitions {
+	return Transitions{
+		StatusTodo:       {StatusInProgress},
+		StatusInProgress: {StatusTodo, StatusDone},
+		StatusDone:       {StatusInProgress},
+	}
+}
+
+// Allows reports whether a todo may move from one status to another.
+func (t Transitions) Allows(from, to Status) bool {
+	for _, next := range t[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats t the way ParseTransitions reads it.
+func (t Transitions) String() string {
+	var pairs []string
+	for _, from := range []Status{StatusTodo, StatusInProgress, StatusDone} {
+		for _, to := range t[from] {
+			pairs = append(pairs, string(from)+">"+string(to))
+		}
+	}
+	return strings.Join(pairs, ",")
+}
+
+// ParseTransitions reads a comma separated list of FROM>TO pairs, for
+// example "TODO>IN_PROGRESS,IN_PROGRESS>DONE".
+func ParseTransitions(s string) (Transitions, error) {
+	t := Transitions{}
+	for _, pair := range strings.Split(s, ",") {
+		parts := strings.Split(strings.TrimSpace(pair), ">")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid transition %q, want FROM>TO", pair)
+		}
+
+		from := Status(strings.TrimSpace(parts[0]))
+		to := Status(strings.TrimSpace(parts[1]))
+		if !from.Valid() || !to.Valid() {
+			return nil, fmt.Errorf("invalid transition %q, unknown status", pair)
+		}
+		t[from] = append(t[from], to)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,17 @@
+package model
+
+// Tag is a label todos can share. Names are unique, so attaching a name
+// that exists reuses its Tag.
+type Tag struct {
+	Id   int64  `xorm:"pk autoincr"`
+	Name string `xorm:"unique notnull"`
+	// Color is a hex color such as "#ff8800", or empty when none is set.
+	Color string `xorm:"notnull default ''"`
+}
+
+// TodoTag attaches a tag to a todo.
+type TodoTag struct {
+	Id     int64 `xorm:"pk autoincr"`
+	TodoId int   `xorm:"unique(todo_tag) index"`
+	TagId  int64 `xorm:"unique(todo_tag)"`
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"time"
+	"unicode/utf8"
+)
+
+// Ellipsis marks text shortened by TruncateText.
+const Ellipsis = "…"
+
+// Todo is a single todo item as stored in the database.
+type Todo struct {
+	Id       int   `xorm:"pk autoincr" `
+	UserId   int64 `xorm:"index unique(client_mutation)"` // owner, see store.WithUser
+	Text     string
+	Done     bool      // kept in sync with Status for older clients
+	Status   Status    `xorm:"varchar(16) notnull default 'TODO'"`
+	Created  time.Time `xorm:"created"`
+	DueDate  time.Time `xorm:"index"` // zero when the todo has no due date
+	Estimate int       // effort in points, 0 when not estimated
+	Priority Priority  `xorm:"index notnull default 2"` // PriorityMedium unless set
+	Position float64   `xorm:"index"`                   // manual order, new todos go last
+	Version  int       `xorm:"version"`                 // Optimistic Locking
+
+	// ClientMutationId is the idempotency key createTodo was called with,
+	// unique per user and nil when none was given.
+	ClientMutationId *string `xorm:"unique(client_mutation)" json:"-"`
+}
+
+// Overdue reports whether the todo has a due date before now and is not
+// done yet.
+func (t *Todo) Overdue(now time.Time) bool {
+	return !t.DueDate.IsZero() && t.DueDate.Before(now) && t.Status != StatusDone
+}
+
+// SetStatus moves the todo to status, updating Done to match.
+func (t *Todo) SetStatus(status Status) {
+	t.Status = status
+	t.Done = status == StatusDone
+}
+
+// SetDone marks the todo done or reopens it. Reopening a done todo puts it
+// back to TODO, while an in-progress todo keeps its status.
+func (t *Todo) SetDone(done bool) {
+	switch {
+	case done:
+		t.SetStatus(StatusDone)
+	case t.Status == StatusDone || t.Status == "":
+		t.SetStatus(StatusTodo)
+	default:
+		t.Done = false
+	}
+}
+
+// TruncateText shortens text to at most maxLen characters, ending it with
+// Ellipsis. Below 1 there is no room for even the Ellipsis, so all of text
+// goes. The boolean reports whether text was changed.
+func TruncateText(text string, maxLen int) (string, bool) {
+	if maxLen < 1 {
+		return "", text != ""
+	}
+	if utf8.RuneCountInString(text) <= maxLen {
+		return text, false
+	}
+
+	runes := []rune(text)
+	return string(runes[:maxLen-1]) + Ellipsis, true
+}
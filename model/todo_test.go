@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetStatus(t *testing.T) {
+	for _, tc := range []struct {
+		status Status
+		done   bool
+	}{
+		{StatusTodo, false},
+		{StatusInProgress, false},
+		{StatusDone, true},
+	} {
+		todo := &Todo{Done: !tc.done}
+		todo.SetStatus(tc.status)
+		if todo.Status != tc.status || todo.Done != tc.done {
+			t.Errorf("SetStatus(%s) = %s done=%v, want done=%v", tc.status, todo.Status, todo.Done, tc.done)
+		}
+	}
+}
+
+func TestSetDone(t *testing.T) {
+	for _, tc := range []struct {
+		from Status
+		done bool
+		want Status
+	}{
+		{"", true, StatusDone},
+		{"", false, StatusTodo},
+		{StatusTodo, true, StatusDone},
+		{StatusTodo, false, StatusTodo},
+		{StatusInProgress, true, StatusDone},
+		{StatusInProgress, false, StatusInProgress},
+		{StatusDone, true, StatusDone},
+		{StatusDone, false, StatusTodo},
+	} {
+		todo := &Todo{Status: tc.from, Done: tc.from == StatusDone}
+		todo.SetDone(tc.done)
+		if todo.Status != tc.want || todo.Done != tc.done {
+			t.Errorf("SetDone(%v) from %q = %s done=%v, want %s done=%v", tc.done, tc.from, todo.Status, todo.Done, tc.want, tc.done)
+		}
+	}
+}
+
+func TestTruncateText(t *testing.T) {
+	for _, tc := range []struct {
+		text    string
+		maxLen  int
+		want    string
+		changed bool
+	}{
+		{"short", 12, "short", false},
+		{"exactly 12 c", 12, "exactly 12 c", false},
+		{"just over twelve", 12, "just over t…", true},
+		{"über-lange Überschrift", 12, "über-lange …", true},
+		{"ab", 1, "…", true},
+		{"ab", 0, "", true},
+		{"ab", -1, "", true},
+		{"", 0, "", false},
+	} {
+		got, changed := TruncateText(tc.text, tc.maxLen)
+		if got != tc.want || changed != tc.changed {
+			t.Errorf("TruncateText(%q, %d) = %q, %v, want %q, %v", tc.text, tc.maxLen, got, changed, tc.want, tc.changed)
+		}
+	}
+}
+
+func TestOverdue(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name    string
+		todo    Todo
+		overdue bool
+	}{
+		{"past due", Todo{Status: StatusTodo, DueDate: now.Add(-time.Hour)}, true},
+		{"past due in progress", Todo{Status: StatusInProgress, DueDate: now.Add(-time.Hour)}, true},
+		{"past due but done", Todo{Status: StatusDone, Done: true, DueDate: now.Add(-time.Hour)}, false},
+		{"due later", Todo{Status: StatusTodo, DueDate: now.Add(time.Hour)}, false},
+		{"no due date", Todo{Status: StatusTodo}, false},
+	} {
+		if got := tc.todo.Overdue(now); got != tc.overdue {
+			t.Errorf("%s: Overdue = %v, want %v", tc.name, got, tc.overdue)
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build nographiql
+// +build nographiql
+
+package main
+
+import "net/http"
+
+// graphiqlHandler has no UI to serve in API-only builds.
+func graphiqlHandler() http.Handler { return nil }
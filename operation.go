@@ -0,0 +1,82 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// hasOperation reports whether query contains an operation of the given
+// type (ast.OperationTypeQuery, ...). Queries that do not parse report
+// false and are left to the executor to reject.
+func hasOperation(query, operation string) bool {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return false
+	}
+
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok && op.Operation == operation {
+			return true
+		}
+	}
+
+	return false
+}
+
+// selectOperation picks the operation of doc that runs for operationName
+// the way the executor does: the one with that name, or the only one when
+// no name is given.
+func selectOperation(doc *ast.Document, operationName string) (*ast.OperationDefinition, error) {
+	var selected *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if operationName == "" {
+			if selected != nil {
+				return nil, errors.New("Must provide operation name if query contains multiple operations.")
+			}
+			selected = op
+		} else if op.Name != nil && op.Name.Value == operationName {
+			selected = op
+		}
+	}
+
+	switch {
+	case selected != nil:
+		return selected, nil
+	case operationName != "":
+		return nil, fmt.Errorf("Unknown operation named %q.", operationName)
+	default:
+		return nil, errors.New("Must provide an operation.")
+	}
+}
+
+// checkSubscription makes sure the operation query runs for operationName
+// is a subscription, and that the document defines no query or mutation
+// next to it, since a subscription document is executed on every event.
+func checkSubscription(query, operationName string) error {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return err
+	}
+
+	op, err := selectOperation(doc, operationName)
+	if err != nil {
+		return err
+	}
+	if op.Operation != ast.OperationTypeSubscription {
+		return errors.New("only subscription operations can be started")
+	}
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok && op.Operation != ast.OperationTypeSubscription {
+			return errors.New("a subscription document cannot also define queries or mutations")
+		}
+	}
+
+	return nil
+}
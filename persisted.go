@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+)
+
+// maxPersistedQueries bounds how many queries memoryQueryStore keeps.
+const maxPersistedQueries = 1000
+
+// queryStore keeps persisted queries by the hex SHA-256 of their text.
+type queryStore interface {
+	Get(hash string) (string, bool)
+	Put(hash, query string)
+}
+
+// memoryQueryStore is a queryStore that lives as long as the process. Once
+// full it stops registering new queries, which then have to be sent in
+// full.
+type memoryQueryStore struct {
+	mu      sync.Mutex
+	queries map[string]string
+}
+
+func newMemoryQueryStore() *memoryQueryStore {
+	return &memoryQueryStore{queries: make(map[string]string)}
+}
+
+func (s *memoryQueryStore) Get(hash string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query, ok := s.queries[hash]
+	return query, ok
+}
+
+func (s *memoryQueryStore) Put(hash, query string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.queries) < maxPersistedQueries {
+		s.queries[hash] = query
+	}
+}
+
+// withPersistedQueries lets clients send a sha256Hash in
+// extensions.persistedQuery instead of the query. A hash that is not
+// registered yet gets a PersistedQueryNotFound error, upon which the client
+// sends query and hash together once to register it. Requests are passed
+// on with the query filled in, so the handlers behind see a plain request.
+func withPersistedQueries(next http.Handler, queries queryStore) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, err := peekRequest(r)
+		if err != nil || req.Extensions.PersistedQuery == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		hash := req.Extensions.PersistedQuery.Sha256Hash
+
+		if req.Query != "" {
+			sum := sha256.Sum256([]byte(req.Query))
+			if hex.EncodeToString(sum[:]) != hash {
+				writePersistedQueryError(w, "provided sha256Hash does not match query", "PERSISTED_QUERY_HASH_MISMATCH")
+				return
+			}
+			queries.Put(hash, req.Query)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		query, ok := queries.Get(hash)
+		if !ok {
+			writePersistedQueryError(w, "PersistedQueryNotFound", "PERSISTED_QUERY_NOT_FOUND")
+			return
+		}
+
+		if r.Method == http.MethodGet {
+			values := r.URL.Query()
+			values.Set("query", query)
+			r.URL.RawQuery = values.Encode()
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		req.Query = query
+		body, err := json.Marshal(req)
+		if err != nil {
+			writeGraphQLError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		r.ContentLength = int64(len(body))
+		r.Header.Set("Content-Type", "application/json")
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writePersistedQueryError answers the way Apollo clients expect: status
+// 200 and the error code in the extensions.
+func writePersistedQueryError(w http.ResponseWriter, message, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []gqlerrors.FormattedError{{
+			Message:    message,
+			Extensions: map[string]interface{}{"code": code},
+		}},
+	})
+}
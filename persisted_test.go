@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestPersistedQueries(t *testing.T) {
+	s := store.NewMemoryStore()
+	if err := s.Create(context.Background(), &model.Todo{Text: "send the invoice", Status: model.StatusTodo}); err != nil {
+		t.Fatal(err)
+	}
+	h := withPersistedQueries(serveGraphQL(newSchema(t, s), time.Second), newMemoryQueryStore())
+
+	query := `{ todoList { Text } }`
+	sum := sha256.Sum256([]byte(query))
+	hash := hex.EncodeToString(sum[:])
+	post := func(query, hash string) graphQLResponse {
+		body, _ := json.Marshal(graphQLRequest{
+			Query:      query,
+			Extensions: requestExtensions{PersistedQuery: &persistedQuery{Version: 1, Sha256Hash: hash}},
+		})
+		r := httptest.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		return decodeResponse(t, serve(h, r))
+	}
+	wantData := `{"todoList":[{"Text":"send the invoice"}]}`
+
+	if res := post("", hash); len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != "PERSISTED_QUERY_NOT_FOUND" {
+		t.Fatalf("unknown hash: errors %+v, want PERSISTED_QUERY_NOT_FOUND", res.Errors)
+	}
+
+	if res := post(query, hash); len(res.Errors) > 0 || string(res.Data) != wantData {
+		t.Fatalf("registering: data %s errors %+v, want %s", res.Data, res.Errors, wantData)
+	}
+
+	if res := post("", hash); len(res.Errors) > 0 || string(res.Data) != wantData {
+		t.Errorf("hash only: data %s errors %+v, want %s", res.Data, res.Errors, wantData)
+	}
+
+	extensions := `{"persistedQuery":{"version":1,"sha256Hash":"` + hash + `"}}`
+	w := serve(h, httptest.NewRequest(http.MethodGet, "/graphql?extensions="+url.QueryEscape(extensions), nil))
+	if res := decodeResponse(t, w); len(res.Errors) > 0 || string(res.Data) != wantData {
+		t.Errorf("GET hash only: data %s errors %+v, want %s", res.Data, res.Errors, wantData)
+	}
+
+	if res := post(`{ todoList { Id } }`, hash); len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != "PERSISTED_QUERY_HASH_MISMATCH" {
+		t.Errorf("wrong hash: errors %+v, want PERSISTED_QUERY_HASH_MISMATCH", res.Errors)
+	}
+}
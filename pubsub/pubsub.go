@@ -0,0 +1,77 @@
+package pubsub
+
+import (
+	"sync"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// ChangeType says what happened to a todo.
+type ChangeType string
+
+const (
+	Created ChangeType = "CREATED"
+	Updated ChangeType = "UPDATED"
+	Deleted ChangeType = "DELETED"
+)
+
+// Event is published after a todo change has been written.
+type Event struct {
+	Type ChangeType
+	Todo model.Todo
+}
+
+// Broker fans events out to every subscriber in the process. A nil *Broker
+// is valid and drops everything published to it.
+type Broker struct {
+	mu     sync.Mutex
+	subs   map[int]chan Event
+	nextID int
+}
+
+// NewBroker returns a Broker without subscribers.
+func NewBroker() *Broker {
+	return &Broker{subs: make(map[int]chan Event)}
+}
+
+// Subscribe registers a subscriber whose channel holds up to buffer
+// pending events. The returned function unsubscribes and closes the
+// channel.
+func (b *Broker) Subscribe(buffer int) (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.nextID
+	b.nextID++
+	ch := make(chan Event, buffer)
+	b.subs[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			delete(b.subs, id)
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers e to every subscriber without blocking. Subscribers
+// whose buffer is full miss the event rather than stalling the writer.
+func (b *Broker) Publish(e Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
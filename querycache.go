@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/printer"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// maxCachedQueries bounds how many responses queryCache holds.
+const maxCachedQueries = 1000
+
+type cachedResponse struct {
+	body    []byte
+	expires time.Time
+}
+
+// queryCache keeps the responses of read-only GraphQL requests for ttl.
+type queryCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	responses map[string]cachedResponse
+}
+
+func newQueryCache(ttl time.Duration) *queryCache {
+	return &queryCache{ttl: ttl, responses: make(map[string]cachedResponse)}
+}
+
+func (c *queryCache) get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res, ok := c.responses[key]
+	if !ok || time.Now().After(res.expires) {
+		return nil, false
+	}
+	return res.body, true
+}
+
+func (c *queryCache) put(key string, body []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.responses) >= maxCachedQueries {
+		for k, res := range c.responses {
+			if now.After(res.expires) {
+				delete(c.responses, k)
+			}
+		}
+	}
+	if len(c.responses) >= maxCachedQueries {
+		c.responses = make(map[string]cachedResponse)
+	}
+	c.responses[key] = cachedResponse{body: body, expires: now.Add(c.ttl)}
+}
+
+// clear drops every response, after a write may have changed them.
+func (c *queryCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.responses = make(map[string]cachedResponse)
+}
+
+// withQueryCache answers repeated read-only requests from cache. The key
+// is the printed query, so formatting does not matter, plus the variables,
+// the operation and who is asking. Requests with a mutation clear the
+// cache and are never cached, nor are responses with errors. A nil cache
+// disables it.
+func withQueryCache(next http.Handler, cache *queryCache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cache == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		req, err := peekRequest(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !readOnly(doc) {
+			next.ServeHTTP(w, r)
+			cache.clear()
+			return
+		}
+
+		key, err := cacheKey(r, req, doc)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if body, ok := cache.get(key); ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(body)
+			return
+		}
+
+		rec := &bodyRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		if rec.status == http.StatusOK && !hasErrors(rec.body.Bytes()) {
+			cache.put(key, rec.body.Bytes())
+		}
+	})
+}
+
+// withCacheClear clears cache after every request that may write, for
+// handlers outside GraphQL such as the REST API.
+func withCacheClear(next http.Handler, cache *queryCache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r)
+		if cache != nil && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			cache.clear()
+		}
+	})
+}
+
+// readOnly reports whether doc holds query operations only.
+func readOnly(doc *ast.Document) bool {
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok && op.Operation != ast.OperationTypeQuery {
+			return false
+		}
+	}
+	return true
+}
+
+func cacheKey(r *http.Request, req *graphQLRequest, doc *ast.Document) (string, error) {
+	printed, _ := printer.Print(doc).(string)
+	variables, err := json.Marshal(req.Variables)
+	if err != nil {
+		return "", err
+	}
+
+	user := strconv.FormatInt(store.UserFrom(r.Context()), 10)
+	admin := strconv.FormatBool(graph.IsAdmin(r.Context()))
+	// pretty responses only differ in whitespace but are cached apart
+	pretty := strconv.FormatBool(wantsPretty(r))
+	return user + "\x00" + admin + "\x00" + pretty + "\x00" + req.OperationName + "\x00" + printed + "\x00" + string(variables), nil
+}
+
+// hasErrors reports whether a GraphQL response body carries errors.
+func hasErrors(body []byte) bool {
+	var res struct {
+		Errors []json.RawMessage `json:"errors"`
+	}
+	return json.Unmarshal(body, &res) != nil || len(res.Errors) > 0
+}
+
+// bodyRecorder passes a response through while keeping a copy of it.
+type bodyRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bodyRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *bodyRecorder) Write(p []byte) (int, error) {
+	r.body.Write(p)
+	return r.ResponseWriter.Write(p)
+}
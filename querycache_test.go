@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// listCountingStore counts the List calls that reach the store.
+type listCountingStore struct {
+	*store.MemoryStore
+	lists int32
+}
+
+func (s *listCountingStore) List(ctx context.Context, sort store.Sort) ([]model.Todo, error) {
+	atomic.AddInt32(&s.lists, 1)
+	return s.MemoryStore.List(ctx, sort)
+}
+
+func TestQueryCache(t *testing.T) {
+	s := &listCountingStore{MemoryStore: store.NewMemoryStore()}
+	h := withQueryCache(serveGraphQL(newSchema(t, s), time.Second), newQueryCache(100*time.Millisecond))
+	list := func(query string) string {
+		t.Helper()
+		res := decodeResponse(t, postQuery(h, query))
+		if len(res.Errors) > 0 {
+			t.Fatalf("%s: %+v", query, res.Errors)
+		}
+		return string(res.Data)
+	}
+	lists := func() int32 { return atomic.LoadInt32(&s.lists) }
+
+	first := list(`{ todoList { Text } }`)
+	// the same query formatted otherwise is the same cache entry
+	if again := list("{\n  todoList {\n    Text\n  }\n}"); again != first {
+		t.Errorf("cached response %s, want %s", again, first)
+	}
+	if n := lists(); n != 1 {
+		t.Errorf("%d List calls for a repeated query, want 1", n)
+	}
+
+	res := decodeResponse(t, postQuery(h, `mutation { createTodo(Text: "not cached") { Id } }`))
+	if len(res.Errors) > 0 {
+		t.Fatal(res.Errors)
+	}
+	if got := list(`{ todoList { Text } }`); !strings.Contains(got, "not cached") {
+		t.Errorf("todoList after a mutation = %s, want the new todo", got)
+	}
+	if n := lists(); n != 2 {
+		t.Errorf("%d List calls after a mutation, want 2", n)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	list(`{ todoList { Text } }`)
+	if n := lists(); n != 3 {
+		t.Errorf("%d List calls once the TTL passed, want 3", n)
+	}
+}
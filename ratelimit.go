@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRateLimitClients bounds how many client buckets are kept.
+const maxRateLimitClients = 10000
+
+// bucket is the token bucket of one client.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter hands every client IP a token bucket refilling at rate
+// tokens per second and holding at most burst.
+type rateLimiter struct {
+	rate  float64
+	burst float64
+	// trustProxy takes the client IP from X-Forwarded-For.
+	trustProxy bool
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+func newRateLimiter(rate float64, burst int, trustProxy bool) *rateLimiter {
+	return &rateLimiter{
+		rate:       rate,
+		burst:      float64(burst),
+		trustProxy: trustProxy,
+		buckets:    make(map[string]*bucket),
+	}
+}
+
+// allow takes a token from the client's bucket. When it is empty it
+// returns false and how long until the next token.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[client]
+	if !ok {
+		l.makeRoom(now)
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+		return false, wait
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// makeRoom keeps the number of buckets under maxRateLimitClients. Buckets
+// that have refilled are the same as fresh ones and go first; if that is
+// not enough an arbitrary one goes. The caller holds mu.
+func (l *rateLimiter) makeRoom(now time.Time) {
+	if len(l.buckets) < maxRateLimitClients {
+		return
+	}
+
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+	for client := range l.buckets {
+		if len(l.buckets) < maxRateLimitClients {
+			break
+		}
+		delete(l.buckets, client)
+	}
+}
+
+// withRateLimit answers 429 with a Retry-After header to clients that
+// used up their bucket. A nil limiter lets everything through.
+func withRateLimit(next http.Handler, limiter *rateLimiter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limiter == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if ok, wait := limiter.allow(clientIP(r, limiter.trustProxy)); !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+			writeGraphQLError(w, http.StatusTooManyRequests,
+				fmt.Sprintf("rate limit exceeded, retry in %d seconds", seconds))
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientIP is the address the request came from. That is the peer
+// address, unless trustProxy is set: then it is the last hop in
+// X-Forwarded-For, the one the proxy in front of the server appended.
+// Earlier hops come from the client and could be anything.
+func clientIP(r *http.Request, trustProxy bool) string {
+	if forwarded := r.Header.Get("X-Forwarded-For"); trustProxy && forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		return strings.TrimSpace(hops[len(hops)-1])
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
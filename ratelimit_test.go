@@ -0,0 +1,57 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	// a token every 50ms, two at once
+	h := withRateLimit(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), newRateLimiter(20, 2, false))
+	call := func(ip string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+		r.RemoteAddr = ip + ":4321"
+		return serve(h, r)
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := call("192.0.2.1"); w.Code != http.StatusOK {
+			t.Fatalf("request %d within the burst: status %d", i+1, w.Code)
+		}
+	}
+
+	w := call("192.0.2.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request over the burst: status %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+	if retry := w.Header().Get("Retry-After"); retry != "1" {
+		t.Errorf("Retry-After = %q, want 1", retry)
+	}
+	if res := decodeResponse(t, w); len(res.Errors) != 1 {
+		t.Errorf("429 body %s, want a GraphQL error", w.Body)
+	}
+
+	if w := call("192.0.2.2"); w.Code != http.StatusOK {
+		t.Errorf("another client: status %d, want %d", w.Code, http.StatusOK)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	if w := call("192.0.2.1"); w.Code != http.StatusOK {
+		t.Errorf("after a refill: status %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/graphql", nil)
+	r.RemoteAddr = "10.0.0.1:4321"
+	r.Header.Set("X-Forwarded-For", "198.51.100.7, 203.0.113.9")
+
+	if ip := clientIP(r, false); ip != "10.0.0.1" {
+		t.Errorf("without trustProxy: %q, want the peer address", ip)
+	}
+	if ip := clientIP(r, true); ip != "203.0.113.9" {
+		t.Errorf("with trustProxy: %q, want the last hop", ip)
+	}
+}
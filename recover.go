@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"runtime/debug"
+)
+
+// withRecover turns a panic in next into a 500 with a GraphQL-shaped
+// error and logs the stack, so one bad request does not take the server
+// down with it or leave the client with a dropped connection.
+func withRecover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			p := recover()
+			if p == nil {
+				return
+			}
+			if p == http.ErrAbortHandler {
+				panic(p)
+			}
+
+			log.Printf("ERROR: panic serving %s %s: %v\n%s", r.Method, r.URL.Path, p, debug.Stack())
+			writeGraphQLError(w, http.StatusInternalServerError, "internal server error")
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// panicStore panics on List, like a resolver with a bug.
+type panicStore struct {
+	*store.MemoryStore
+}
+
+func (panicStore) List(ctx context.Context, sort store.Sort) ([]model.Todo, error) {
+	panic("boom")
+}
+
+// quietLog drops the stacks the recovery logs for the rest of the test.
+func quietLog(t *testing.T) {
+	log.SetOutput(ioutil.Discard)
+	t.Cleanup(func() { log.SetOutput(os.Stderr) })
+}
+
+func TestPanickingResolver(t *testing.T) {
+	quietLog(t)
+	s := panicStore{store.NewMemoryStore()}
+	if err := s.Create(context.Background(), &model.Todo{Text: "still here", Status: model.StatusTodo}); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(withRecover(serveGraphQL(newSchema(t, s), time.Second)))
+	defer srv.Close()
+
+	post := func(query string) graphQLResponse {
+		t.Helper()
+		resp, err := http.Post(srv.URL, "application/json", newQueryPost(query).Body)
+		if err != nil {
+			t.Fatalf("%s: %v", query, err)
+		}
+		defer resp.Body.Close()
+
+		var res graphQLResponse
+		if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+			t.Fatalf("%s: decoding: %v", query, err)
+		}
+		return res
+	}
+
+	res := post(`{ todoList { Id } todo(Id: 1) { Text } }`)
+	if len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != graph.CodeInternal {
+		t.Fatalf("errors %+v, want one INTERNAL error", res.Errors)
+	}
+	if strings.Contains(res.Errors[0].Message, "boom") {
+		t.Errorf("message %q leaks the panic", res.Errors[0].Message)
+	}
+	// the other field of the same request still resolves
+	if !strings.Contains(string(res.Data), "still here") {
+		t.Errorf("data %s, want the todo resolved despite the panic", res.Data)
+	}
+
+	// and the server keeps answering
+	if res := post(`{ todo(Id: 1) { Text } }`); len(res.Errors) > 0 {
+		t.Errorf("request after the panic: errors %+v", res.Errors)
+	}
+}
+
+func TestWithRecover(t *testing.T) {
+	quietLog(t)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) { panic("boom") })
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	srv := httptest.NewServer(withRecover(mux))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/panic")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("panicking handler: status %d, want %d", resp.StatusCode, http.StatusInternalServerError)
+	}
+	if !strings.Contains(string(body), `"errors"`) || strings.Contains(string(body), "boom") {
+		t.Errorf("panicking handler: body %s, want a GraphQL error without the panic", body)
+	}
+
+	resp, err = http.Get(srv.URL + "/ok")
+	if err != nil {
+		t.Fatalf("request after the panic: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("request after the panic: status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
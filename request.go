@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+)
+
+// errUnsupportedMediaType is returned for bodies that are neither JSON nor
+// a bare GraphQL query.
+var errUnsupportedMediaType = errors.New("unsupported Content-Type, send application/json or application/graphql")
+
+// graphQLRequest is the JSON body of a POST to /graphql, the query string
+// of a GET, and the payload of a subscription start message.
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+	Extensions    requestExtensions      `json:"extensions"`
+}
+
+// requestExtensions are the protocol extensions a request may carry.
+type requestExtensions struct {
+	PersistedQuery *persistedQuery `json:"persistedQuery,omitempty"`
+}
+
+// persistedQuery names a query by hash instead of sending its text, as in
+// Apollo's automatic persisted queries.
+type persistedQuery struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+// peekRequest decodes the GraphQL request body and puts the bytes back so
+// the next handler can read it again.
+func peekRequest(r *http.Request) (*graphQLRequest, error) {
+	if r.Method == http.MethodGet {
+		return queryRequest(r.URL.Query())
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if err != nil {
+		// replay the failure too, so the handler that reads the body for
+		// real reports it
+		r.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err}))
+		return nil, err
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return parseRequest(r.Header.Get("Content-Type"), body)
+}
+
+// readRequest reads and decodes the GraphQL request body, or the query
+// string of a GET.
+func readRequest(r *http.Request) (*graphQLRequest, error) {
+	if r.Method == http.MethodGet {
+		return queryRequest(r.URL.Query())
+	}
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRequest(r.Header.Get("Content-Type"), body)
+}
+
+// parseRequest decodes body according to contentType. application/graphql
+// bodies are the query itself; anything without a Content-Type is read as
+// JSON like before.
+func parseRequest(contentType string, body []byte) (*graphQLRequest, error) {
+	mediaType := "application/json"
+	if contentType != "" {
+		var err error
+		if mediaType, _, err = mime.ParseMediaType(contentType); err != nil {
+			return nil, errUnsupportedMediaType
+		}
+	}
+
+	req := &graphQLRequest{}
+	switch mediaType {
+	case "application/json":
+		if err := json.Unmarshal(body, req); err != nil {
+			return nil, err
+		}
+	case "application/graphql":
+		req.Query = string(body)
+	default:
+		return nil, errUnsupportedMediaType
+	}
+
+	return req, nil
+}
+
+// queryRequest decodes a GET request's query string. variables and
+// extensions are JSON-encoded, as in the GraphQL over HTTP convention.
+func queryRequest(values url.Values) (*graphQLRequest, error) {
+	req := &graphQLRequest{
+		Query:         values.Get("query"),
+		OperationName: values.Get("operationName"),
+	}
+	if variables := values.Get("variables"); variables != "" {
+		if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+			return nil, err
+		}
+	}
+	if extensions := values.Get("extensions"); extensions != "" {
+		if err := json.Unmarshal([]byte(extensions), &req.Extensions); err != nil {
+			return nil, err
+		}
+	}
+
+	return req, nil
+}
+
+// errReader fails every read with err.
+type errReader struct{ err error }
+
+func (e errReader) Read([]byte) (int, error) { return 0, e.err }
+
+// withBodyLimit caps request bodies at limit bytes. Reading past it fails,
+// which isBodyTooLarge recognizes. A limit of 0 disables the cap.
+func withBodyLimit(next http.Handler, limit int64) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if limit > 0 {
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// isBodyTooLarge reports whether err comes from reading past the cap set
+// by withBodyLimit. http.MaxBytesReader only exposes it by its message.
+func isBodyTooLarge(err error) bool {
+	return err != nil && err.Error() == "http: request body too large"
+}
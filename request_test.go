@@ -0,0 +1,143 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestReadRequest(t *testing.T) {
+	body := `{
+		"query": "query Todo($id: Int) { todo(Id: $id) { Text } }",
+		"variables": {"id": 2},
+		"operationName": "Todo",
+		"extensions": {"persistedQuery": {"version": 1, "sha256Hash": "abc"}}
+	}`
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+
+	req, err := readRequest(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Query != "query Todo($id: Int) { todo(Id: $id) { Text } }" || req.OperationName != "Todo" {
+		t.Errorf("decoded %+v", req)
+	}
+	if id, _ := req.Variables["id"].(float64); id != 2 {
+		t.Errorf("variables = %v", req.Variables)
+	}
+	if pq := req.Extensions.PersistedQuery; pq == nil || pq.Version != 1 || pq.Sha256Hash != "abc" {
+		t.Errorf("persistedQuery = %+v", pq)
+	}
+}
+
+func TestReadRequestFromQueryString(t *testing.T) {
+	values := url.Values{
+		"query":         {"query Todo($id: Int) { todo(Id: $id) { Text } }"},
+		"variables":     {`{"id": 2}`},
+		"operationName": {"Todo"},
+	}
+	r := httptest.NewRequest(http.MethodGet, "/graphql?"+values.Encode(), nil)
+
+	req, err := readRequest(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Query != values.Get("query") || req.OperationName != "Todo" {
+		t.Errorf("decoded %+v", req)
+	}
+	if id, _ := req.Variables["id"].(float64); id != 2 {
+		t.Errorf("variables = %v", req.Variables)
+	}
+
+	r = httptest.NewRequest(http.MethodGet, "/graphql?query={todoList{Id}}&variables=nope", nil)
+	if _, err := readRequest(r); err == nil {
+		t.Error("variables that are not JSON were accepted")
+	}
+}
+
+func TestPeekRequestKeepsBody(t *testing.T) {
+	body := `{"query": "{ todoList { Id } }"}`
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body))
+
+	for i := 0; i < 2; i++ {
+		req, err := peekRequest(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if req.Query != "{ todoList { Id } }" {
+			t.Errorf("peek %d decoded %+v", i, req)
+		}
+	}
+	if req, err := readRequest(r); err != nil || req.Query != "{ todoList { Id } }" {
+		t.Errorf("readRequest after peeking = %+v, %v", req, err)
+	}
+}
+
+func TestBodyLimit(t *testing.T) {
+	graphqlHandler := withBodyLimit(serveGraphQL(newSchema(t, store.NewMemoryStore()), time.Second), 128)
+	restHandler := withBodyLimit(serveREST(store.NewMemoryStore(), nil), 128)
+	small := `{ todoList { Id } }`
+	big := `{ todoList { Id ` + strings.Repeat("Text ", 20) + `} }`
+
+	if w := postQuery(graphqlHandler, small); w.Code != http.StatusOK {
+		t.Errorf("small /graphql body: status %d, body %s", w.Code, w.Body)
+	}
+	if w := postQuery(graphqlHandler, big); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("big /graphql body: status %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+
+	body := `{"Text": "` + strings.Repeat("a", 200) + `"}`
+	if w := restCall(restHandler, http.MethodPost, "/api/todos", body); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("big REST body: status %d, want %d", w.Code, http.StatusRequestEntityTooLarge)
+	}
+}
+
+func TestParseRequestContentTypes(t *testing.T) {
+	const query = "{ todoList { Id } }"
+	jsonBody := []byte(`{"query": "{ todoList { Id } }"}`)
+
+	for _, tc := range []struct {
+		contentType string
+		body        []byte
+		err         error
+	}{
+		{"application/json", jsonBody, nil},
+		{"application/json; charset=utf-8", jsonBody, nil},
+		{"", jsonBody, nil},
+		{"application/graphql", []byte(query), nil},
+		{"text/plain", []byte(query), errUnsupportedMediaType},
+		{"application/x-www-form-urlencoded", []byte("query=" + url.QueryEscape(query)), errUnsupportedMediaType},
+		{"not a media type;;", jsonBody, errUnsupportedMediaType},
+	} {
+		req, err := parseRequest(tc.contentType, tc.body)
+		if err != tc.err {
+			t.Errorf("%q: err = %v, want %v", tc.contentType, err, tc.err)
+			continue
+		}
+		if err == nil && req.Query != query {
+			t.Errorf("%q: query = %q, want %q", tc.contentType, req.Query, query)
+		}
+	}
+}
+
+func TestServeGraphQLUnsupportedMediaType(t *testing.T) {
+	h := serveGraphQL(newSchema(t, store.NewMemoryStore()), time.Second)
+
+	r := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{ todoList { Id } }"))
+	r.Header.Set("Content-Type", "text/plain")
+	if w := serve(h, r); w.Code != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain body: status %d, want %d", w.Code, http.StatusUnsupportedMediaType)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader("{ todoList { Id } }"))
+	r.Header.Set("Content-Type", "application/graphql")
+	if w := serve(h, r); w.Code != http.StatusOK {
+		t.Errorf("application/graphql body: status %d, body %s", w.Code, w.Body)
+	}
+}
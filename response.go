@@ -0,0 +1,52 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+)
+
+// writeGraphQLError answers with status and a GraphQL-shaped error body so
+// clients can handle transport failures like any other GraphQL error.
+func writeGraphQLError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"errors": []gqlerrors.FormattedError{gqlerrors.NewFormattedError(message)},
+	})
+}
+
+// wantsPretty reports whether the client asked for indented JSON, with
+// ?pretty=true or an X-Pretty: true header, to read the response by hand.
+func wantsPretty(r *http.Request) bool {
+	pretty := r.URL.Query().Get("pretty")
+	if pretty == "" {
+		pretty = r.Header.Get("X-Pretty")
+	}
+	ok, _ := strconv.ParseBool(pretty)
+	return ok
+}
+
+// addErrorCodes sets a code in the extensions of every error that has
+// none yet. Resolver errors already carry one, so an error without a path
+// comes from parsing or validating the document; the rest are internal.
+func addErrorCodes(errs []gqlerrors.FormattedError) {
+	for i := range errs {
+		if _, ok := errs[i].Extensions["code"]; ok {
+			continue
+		}
+
+		code := graph.CodeInternal
+		if len(errs[i].Path) == 0 {
+			code = graph.CodeValidation
+		}
+		if errs[i].Extensions == nil {
+			errs[i].Extensions = map[string]interface{}{}
+		}
+		errs[i].Extensions["code"] = code
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql/gqlerrors"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestAddErrorCodes(t *testing.T) {
+	errs := []gqlerrors.FormattedError{
+		{Message: "Syntax Error GraphQL request (1:3) Expected Name"},
+		{Message: "broke", Path: []interface{}{"todoList"}},
+		{Message: "admin access required", Path: []interface{}{"config"}, Extensions: map[string]interface{}{"code": graph.CodeForbidden}},
+	}
+	addErrorCodes(errs)
+
+	for i, want := range []string{graph.CodeValidation, graph.CodeInternal, graph.CodeForbidden} {
+		if code := errs[i].Extensions["code"]; code != want {
+			t.Errorf("%q: code = %v, want %s", errs[i].Message, code, want)
+		}
+	}
+}
+
+func TestDocumentErrorCodes(t *testing.T) {
+	h := newMux(t, nil)
+
+	for _, query := range []string{
+		`{ todoList { Id `,
+		`{ todoList { colour } }`,
+	} {
+		res := decodeResponse(t, postQuery(h, query))
+		if len(res.Errors) == 0 {
+			t.Errorf("%s: no errors", query)
+		}
+		for _, e := range res.Errors {
+			if e.Extensions["code"] != graph.CodeValidation {
+				t.Errorf("%s: error %+v, want it coded %s", query, e, graph.CodeValidation)
+			}
+		}
+	}
+}
+
+func TestWantsPretty(t *testing.T) {
+	for _, tc := range []struct {
+		url, header string
+		pretty      bool
+	}{
+		{"/graphql", "", false},
+		{"/graphql?pretty=true", "", true},
+		{"/graphql?pretty=1", "", true},
+		{"/graphql?pretty=false", "true", false},
+		{"/graphql?pretty=yes", "", false},
+		{"/graphql", "true", true},
+	} {
+		r := httptest.NewRequest(http.MethodGet, tc.url, nil)
+		if tc.header != "" {
+			r.Header.Set("X-Pretty", tc.header)
+		}
+		if got := wantsPretty(r); got != tc.pretty {
+			t.Errorf("%s with X-Pretty %q: wantsPretty = %v, want %v", tc.url, tc.header, got, tc.pretty)
+		}
+	}
+}
+
+func TestPrettyResponse(t *testing.T) {
+	s := store.NewMemoryStore()
+	if err := s.Create(context.Background(), &model.Todo{Text: "read it by hand", Status: model.StatusTodo}); err != nil {
+		t.Fatal(err)
+	}
+	h := serveGraphQL(newSchema(t, s), time.Second)
+	query := `{ todoList { Id Text Done } }`
+
+	compact := postQuery(h, query).Body.Bytes()
+	r := newQueryPost(query)
+	r.URL.RawQuery = "pretty=true"
+	pretty := serve(h, r).Body.Bytes()
+
+	if bytes.Contains(compact, []byte("\n  ")) {
+		t.Errorf("default response is indented: %s", compact)
+	}
+	if !bytes.Contains(pretty, []byte("\n  \"data\": {\n")) {
+		t.Errorf("pretty response is not indented: %s", pretty)
+	}
+
+	var a, b interface{}
+	if err := json.Unmarshal(compact, &a); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(pretty, &b); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("pretty response %s differs from %s beyond whitespace", pretty, compact)
+	}
+}
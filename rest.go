@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/pubsub"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+const restPrefix = "/api/todos"
+
+// errEditConflict reports a PATCH that lost the race against another write
+// of the same todo.
+var errEditConflict = errors.New("the todo was changed by another request, try again")
+
+// todoPatch is the body of POST and PATCH requests. Nil fields are left
+// untouched by PATCH.
+type todoPatch struct {
+	Text   *string
+	Done   *bool
+	Status *model.Status
+}
+
+// serveREST is a plain JSON alternative to the GraphQL endpoint, backed by
+// the same store:
+//
+//	GET    /api/todos       list todos
+//	POST   /api/todos       create a todo
+//	GET    /api/todos/{id}  get one todo
+//	PATCH  /api/todos/{id}  change Text, Done or Status
+//	DELETE /api/todos/{id}  delete a todo
+func serveREST(s store.TodoStore, broker *pubsub.Broker) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rest := strings.Trim(strings.TrimPrefix(r.URL.Path, restPrefix), "/")
+		if rest == "" {
+			switch r.Method {
+			case http.MethodGet:
+				listTodos(w, r, s)
+			case http.MethodPost:
+				createTodo(w, r, s, broker)
+			default:
+				w.Header().Set("Allow", "GET, POST")
+				writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+			}
+			return
+		}
+
+		id, err := strconv.Atoi(rest)
+		if err != nil {
+			writeJSONError(w, http.StatusNotFound, "not found")
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			getTodo(w, r, s, id)
+		case http.MethodPatch:
+			patchTodo(w, r, s, broker, id)
+		case http.MethodDelete:
+			deleteTodo(w, r, s, broker, id)
+		default:
+			w.Header().Set("Allow", "GET, PATCH, DELETE")
+			writeJSONError(w, http.StatusMethodNotAllowed, "method not allowed")
+		}
+	}
+}
+
+func listTodos(w http.ResponseWriter, r *http.Request, s store.TodoStore) {
+	all, err := s.List(r.Context(), store.Sort{})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if all == nil {
+		all = []model.Todo{}
+	}
+
+	writeJSON(w, http.StatusOK, all)
+}
+
+func createTodo(w http.ResponseWriter, r *http.Request, s store.TodoStore, broker *pubsub.Broker) {
+	var patch todoPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if patch.Text == nil || *patch.Text == "" {
+		writeJSONError(w, http.StatusBadRequest, "Text is required")
+		return
+	}
+
+	if !validPatch(w, patch) {
+		return
+	}
+
+	todo := &model.Todo{}
+	todo.SetStatus(model.StatusTodo)
+	applyPatch(todo, patch)
+
+	if err := s.Create(r.Context(), todo); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	broker.Publish(pubsub.Event{Type: pubsub.Created, Todo: *todo})
+
+	writeJSON(w, http.StatusCreated, todo)
+}
+
+func getTodo(w http.ResponseWriter, r *http.Request, s store.TodoStore, id int) {
+	todo, err := s.Get(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, todo)
+}
+
+func patchTodo(w http.ResponseWriter, r *http.Request, s store.TodoStore, broker *pubsub.Broker, id int) {
+	var patch todoPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if !validPatch(w, patch) {
+		return
+	}
+
+	var todo *model.Todo
+	var cols []string
+	err := s.WithTx(r.Context(), func(tx store.TodoStore) error {
+		var err error
+		if todo, err = tx.Get(r.Context(), id); err != nil {
+			return err
+		}
+		if cols = applyPatch(todo, patch); len(cols) == 0 {
+			return nil
+		}
+
+		err = tx.Update(r.Context(), todo, cols...)
+		if err == store.ErrNotFound {
+			// the todo was there a moment ago, so unless it has been
+			// deleted since, its version moved on under us
+			if _, err := tx.Get(r.Context(), id); err != nil {
+				return err
+			}
+			return errEditConflict
+		}
+		return err
+	})
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if len(cols) > 0 {
+		broker.Publish(pubsub.Event{Type: pubsub.Updated, Todo: *todo})
+	}
+
+	writeJSON(w, http.StatusOK, todo)
+}
+
+func deleteTodo(w http.ResponseWriter, r *http.Request, s store.TodoStore, broker *pubsub.Broker, id int) {
+	todo, err := s.Get(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if err := s.Delete(r.Context(), id); err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	broker.Publish(pubsub.Event{Type: pubsub.Deleted, Todo: *todo})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validPatch answers 400 and returns false when a field of patch is
+// invalid.
+func validPatch(w http.ResponseWriter, patch todoPatch) bool {
+	if patch.Status != nil && !patch.Status.Valid() {
+		writeJSONError(w, http.StatusBadRequest, "invalid Status "+string(*patch.Status))
+		return false
+	}
+
+	return true
+}
+
+// applyPatch copies the fields present in patch onto todo and returns the
+// columns it touched. Status wins over Done, as in the updateTodo
+// mutation.
+func applyPatch(todo *model.Todo, patch todoPatch) []string {
+	var cols []string
+	if patch.Text != nil {
+		todo.Text = *patch.Text
+		cols = append(cols, "text")
+	}
+	if patch.Done != nil || patch.Status != nil {
+		cols = append(cols, "done", "status")
+	}
+	if patch.Done != nil {
+		todo.SetDone(*patch.Done)
+	}
+	if patch.Status != nil {
+		todo.SetStatus(*patch.Status)
+	}
+
+	return cols
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	if err == store.ErrNotFound {
+		writeJSONError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err == store.ErrDuplicateText || err == errEditConflict {
+		writeJSONError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if store.IsDiskFull(err) {
+		log.Printf("ERROR: database storage full, writes are failing: %v", err)
+		writeJSONError(w, http.StatusServiceUnavailable, "storage is full, try again later")
+		return
+	}
+
+	writeJSONError(w, http.StatusInternalServerError, err.Error())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// writeDecodeError reports a request body that could not be decoded.
+func writeDecodeError(w http.ResponseWriter, err error) {
+	if isBodyTooLarge(err) {
+		writeJSONError(w, http.StatusRequestEntityTooLarge, "request body too large")
+		return
+	}
+	writeJSONError(w, http.StatusBadRequest, err.Error())
+}
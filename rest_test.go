@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// restCall sends method to path on h with body as JSON, if any.
+func restCall(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return serve(h, r)
+}
+
+// decodeTodo reads the todo answered in w.
+func decodeTodo(t *testing.T, w *httptest.ResponseRecorder) model.Todo {
+	t.Helper()
+
+	var todo model.Todo
+	if err := json.Unmarshal(w.Body.Bytes(), &todo); err != nil {
+		t.Fatalf("decoding %q: %v", w.Body.String(), err)
+	}
+	return todo
+}
+
+func TestREST(t *testing.T) {
+	h := serveREST(store.NewMemoryStore(), nil)
+
+	w := restCall(h, http.MethodPost, "/api/todos", `{"Text": "water the plants"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("POST: status %d, body %s", w.Code, w.Body)
+	}
+	created := decodeTodo(t, w)
+	if created.Text != "water the plants" || created.Status != model.StatusTodo {
+		t.Errorf("POST created %+v", created)
+	}
+	one := "/api/todos/1"
+
+	w = restCall(h, http.MethodGet, one, "")
+	if w.Code != http.StatusOK || decodeTodo(t, w).Text != "water the plants" {
+		t.Errorf("GET %s: status %d, body %s", one, w.Code, w.Body)
+	}
+
+	w = restCall(h, http.MethodPatch, one, `{"Done": true}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PATCH: status %d, body %s", w.Code, w.Body)
+	}
+	if patched := decodeTodo(t, w); !patched.Done || patched.Status != model.StatusDone || patched.Text != "water the plants" {
+		t.Errorf("PATCH answered %+v", patched)
+	}
+
+	w = restCall(h, http.MethodGet, "/api/todos", "")
+	var list []model.Todo
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("GET list: status %d, body %s", w.Code, w.Body)
+	}
+	if len(list) != 1 || !list[0].Done {
+		t.Errorf("GET list = %+v", list)
+	}
+
+	w = restCall(h, http.MethodDelete, one, "")
+	if w.Code != http.StatusNoContent {
+		t.Errorf("DELETE: status %d, body %s", w.Code, w.Body)
+	}
+	if w = restCall(h, http.MethodGet, one, ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET after DELETE: status %d", w.Code)
+	}
+}
+
+func TestRESTErrors(t *testing.T) {
+	h := serveREST(store.NewMemoryStore(), nil)
+
+	for _, tc := range []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodGet, "/api/todos/404", "", http.StatusNotFound},
+		{http.MethodPatch, "/api/todos/404", `{"Done": true}`, http.StatusNotFound},
+		{http.MethodDelete, "/api/todos/404", "", http.StatusNotFound},
+		{http.MethodGet, "/api/todos/abc", "", http.StatusNotFound},
+		{http.MethodPost, "/api/todos", `{}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/todos", `{"Text": "a", "Status": "LATER"}`, http.StatusBadRequest},
+		{http.MethodPost, "/api/todos", `not json`, http.StatusBadRequest},
+		{http.MethodPut, "/api/todos", "", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/api/todos/1", "", http.StatusMethodNotAllowed},
+	} {
+		if w := restCall(h, tc.method, tc.path, tc.body); w.Code != tc.status {
+			t.Errorf("%s %s: status %d, want %d", tc.method, tc.path, w.Code, tc.status)
+		}
+	}
+}
+
+// fullStore fails every write the way a database on a full disk does.
+type fullStore struct {
+	*store.MemoryStore
+}
+
+func (fullStore) Create(ctx context.Context, todo *model.Todo) error {
+	return errors.New("database or disk is full")
+}
+
+func TestRESTDiskFull(t *testing.T) {
+	h := serveREST(fullStore{store.NewMemoryStore()}, nil)
+
+	w := restCall(h, http.MethodPost, "/api/todos", `{"Text": "one too many"}`)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("POST on a full disk: status %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+	if strings.Contains(w.Body.String(), "database or disk is full") {
+		t.Errorf("POST on a full disk: body %s leaks the driver error", w.Body)
+	}
+}
+
+// staleStore fails every update inside a transaction the way a todo whose
+// version moved on does on the database, recording the columns it was
+// asked to write.
+type staleStore struct {
+	*store.MemoryStore
+	cols []string
+}
+
+func (s *staleStore) WithTx(ctx context.Context, fn func(tx store.TodoStore) error) error {
+	return s.MemoryStore.WithTx(ctx, func(tx store.TodoStore) error {
+		return fn(staleTx{tx, s})
+	})
+}
+
+type staleTx struct {
+	store.TodoStore
+	s *staleStore
+}
+
+func (tx staleTx) Update(ctx context.Context, todo *model.Todo, cols ...string) error {
+	tx.s.cols = cols
+	return store.ErrNotFound
+}
+
+func TestRESTPatchConflict(t *testing.T) {
+	s := &staleStore{MemoryStore: store.NewMemoryStore()}
+	h := serveREST(s, nil)
+	if w := restCall(h, http.MethodPost, "/api/todos", `{"Text": "a"}`); w.Code != http.StatusCreated {
+		t.Fatalf("POST: status %d, body %s", w.Code, w.Body)
+	}
+
+	w := restCall(h, http.MethodPatch, "/api/todos/1", `{"Done": true}`)
+	if w.Code != http.StatusConflict {
+		t.Errorf("PATCH of a stale todo: status %d, want %d", w.Code, http.StatusConflict)
+	}
+	if want := []string{"done", "status"}; strings.Join(s.cols, ",") != strings.Join(want, ",") {
+		t.Errorf("PATCH of Done wrote columns %v, want %v", s.cols, want)
+	}
+	if todo, err := s.Get(context.Background(), 1); err != nil || todo.Done {
+		t.Errorf("todo after the conflict = %+v, %v, want it undone", todo, err)
+	}
+
+	if w := restCall(h, http.MethodPatch, "/api/todos/2", `{"Done": true}`); w.Code != http.StatusNotFound {
+		t.Errorf("PATCH of a missing todo: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestRESTUniqueText(t *testing.T) {
+	s := store.NewMemoryStore()
+	if err := s.RequireUniqueText(); err != nil {
+		t.Fatal(err)
+	}
+	h := serveREST(s, nil)
+
+	for _, text := range []string{"water the plants", "feed the cat"} {
+		if w := restCall(h, http.MethodPost, "/api/todos", `{"Text": "`+text+`"}`); w.Code != http.StatusCreated {
+			t.Fatalf("POST %q: status %d, body %s", text, w.Code, w.Body)
+		}
+	}
+
+	for _, call := range []struct{ method, path string }{
+		{http.MethodPost, "/api/todos"},
+		{http.MethodPatch, "/api/todos/2"},
+	} {
+		w := restCall(h, call.method, call.path, `{"Text": "water the plants"}`)
+		if w.Code != http.StatusConflict {
+			t.Errorf("%s %s with a taken text: status %d, want %d", call.method, call.path, w.Code, http.StatusConflict)
+		}
+	}
+}
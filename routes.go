@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// serveRoot answers / with the GraphiQL UI when ui is set, and every path
+// no other handler is registered for with a JSON 404.
+func serveRoot(ui http.Handler) http.Handler {
+	if ui != nil {
+		ui = withMethods(ui, http.MethodGet, http.MethodHead)
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" && ui != nil {
+			ui.ServeHTTP(w, r)
+			return
+		}
+		writeGraphQLError(w, http.StatusNotFound, "not found")
+	})
+}
+
+// withMethods answers requests with any method but the given ones with a
+// 405 listing the allowed methods in the Allow header.
+func withMethods(next http.Handler, methods ...string) http.Handler {
+	allow := strings.Join(methods, ", ")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, method := range methods {
+			if r.Method == method {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		w.Header().Set("Allow", allow)
+		writeGraphQLError(w, http.StatusMethodNotAllowed, "method not allowed")
+	})
+}
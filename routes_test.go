@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// newMux registers / and /graphql the way main does, with ui as the
+// GraphiQL handler.
+func newMux(t *testing.T, ui http.Handler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle("/", serveRoot(ui))
+	mux.Handle("/graphql", withMethods(serveGraphQL(newSchema(t, store.NewMemoryStore()), time.Second), http.MethodGet, http.MethodPost))
+	return mux
+}
+
+// TestAPIWithoutGraphiQL runs the API the way a nographiql build or
+// GRAPHIQL=false leaves it, with no UI handler.
+func TestAPIWithoutGraphiQL(t *testing.T) {
+	mux := newMux(t, nil)
+
+	w := postQuery(mux, `mutation { createTodo(Text: "no ui needed") { Id } }`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("POST /graphql: status %d, body %s", w.Code, w.Body)
+	}
+	if res := decodeResponse(t, w); len(res.Errors) > 0 {
+		t.Fatalf("POST /graphql: errors %+v", res.Errors)
+	}
+
+	w = serve(mux, httptest.NewRequest(http.MethodGet, "/graphql?query={todoList{Text}}", nil))
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "no ui needed") {
+		t.Errorf("GET /graphql: status %d, body %s", w.Code, w.Body)
+	}
+
+	if w = serve(mux, httptest.NewRequest(http.MethodGet, "/", nil)); w.Code != http.StatusNotFound {
+		t.Errorf("GET / without the UI: status %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestServeRoot(t *testing.T) {
+	stubUI := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("<title>GraphiQL</title>"))
+	})
+
+	for _, tc := range []struct {
+		env  string
+		want int
+	}{
+		{"", http.StatusOK},
+		{"true", http.StatusOK},
+		{"false", http.StatusNotFound},
+	} {
+		t.Setenv("ENABLE_GRAPHIQL", tc.env)
+		cfg, err := loadConfig()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ui http.Handler
+		if cfg.GraphiQL {
+			ui = stubUI
+		}
+		mux := newMux(t, ui)
+
+		w := serve(mux, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != tc.want {
+			t.Errorf("ENABLE_GRAPHIQL=%q: GET / status %d, want %d", tc.env, w.Code, tc.want)
+		}
+		if ui := strings.Contains(w.Body.String(), "GraphiQL"); ui != (tc.want == http.StatusOK) {
+			t.Errorf("ENABLE_GRAPHIQL=%q: GET / body %q", tc.env, w.Body)
+		}
+
+		if w = postQuery(mux, `{ todoList { Id } }`); w.Code != http.StatusOK {
+			t.Errorf("ENABLE_GRAPHIQL=%q: POST /graphql status %d, want %d", tc.env, w.Code, http.StatusOK)
+		}
+	}
+}
+
+func TestRoutesNotFoundAndMethods(t *testing.T) {
+	mux := newMux(t, http.NotFoundHandler())
+
+	for _, path := range []string{"/nothing/here", "/graphql/extra"} {
+		w := serve(mux, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != http.StatusNotFound {
+			t.Errorf("GET %s: status %d, want %d", path, w.Code, http.StatusNotFound)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+			t.Errorf("GET %s: Content-Type %q, want application/json", path, ct)
+		}
+		if res := decodeResponse(t, w); len(res.Errors) != 1 {
+			t.Errorf("GET %s: body %s, want one GraphQL error", path, w.Body)
+		}
+	}
+
+	for _, tc := range []struct{ method, path, allow string }{
+		{http.MethodDelete, "/graphql", "GET, POST"},
+		{http.MethodPut, "/graphql", "GET, POST"},
+		{http.MethodPost, "/", "GET, HEAD"},
+	} {
+		w := serve(mux, httptest.NewRequest(tc.method, tc.path, nil))
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("%s %s: status %d, want %d", tc.method, tc.path, w.Code, http.StatusMethodNotAllowed)
+		}
+		if allow := w.Header().Get("Allow"); allow != tc.allow {
+			t.Errorf("%s %s: Allow = %q, want %q", tc.method, tc.path, allow, tc.allow)
+		}
+		if res := decodeResponse(t, w); len(res.Errors) != 1 {
+			t.Errorf("%s %s: body %s, want one GraphQL error", tc.method, tc.path, w.Body)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+)
+
+// serveSchema answers with the SDL of s, for code generators and other
+// client tooling. The schema cannot change while the server runs, so it
+// is printed once, and clients that send its ETag back in If-None-Match
+// get a 304.
+func serveSchema(s graphql.Schema) http.HandlerFunc {
+	return serveDocument("text/plain; charset=utf-8", []byte(graph.PrintSchema(s)))
+}
+
+// serveSchemaJSON is serveSchema for tools that read the introspection
+// result rather than SDL.
+func serveSchemaJSON(s graphql.Schema) (http.HandlerFunc, error) {
+	body, err := graph.IntrospectSchema(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return serveDocument("application/json", body), nil
+}
+
+// serveDocument answers every request with body, or with 304 Not Modified
+// when If-None-Match names the ETag of body.
+func serveDocument(contentType string, body []byte) http.HandlerFunc {
+	sum := sha256.Sum256(body)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Write(body)
+	}
+}
+
+// etagMatches reports whether the If-None-Match header value names etag.
+// The comparison is weak, as RFC 7232 asks for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+
+	return false
+}
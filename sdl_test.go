@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestSchemaETag(t *testing.T) {
+	handlers := func() map[string]http.Handler {
+		schema := newSchema(t, store.NewMemoryStore())
+		schemaJSON, err := serveSchemaJSON(schema)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return map[string]http.Handler{
+			"/schema.graphql": serveSchema(schema),
+			"/schema.json":    schemaJSON,
+		}
+	}
+	// a second schema, built the same way the next server start builds it
+	again := handlers()
+
+	for path, h := range handlers() {
+		w := serve(h, httptest.NewRequest(http.MethodGet, path, nil))
+		etag := w.Header().Get("ETag")
+		if w.Code != http.StatusOK || etag == "" || w.Body.Len() == 0 {
+			t.Fatalf("GET %s: status %d, ETag %q, %d bytes", path, w.Code, etag, w.Body.Len())
+		}
+		body := w.Body.String()
+
+		if got := serve(again[path], httptest.NewRequest(http.MethodGet, path, nil)); got.Header().Get("ETag") != etag || got.Body.String() != body {
+			t.Errorf("GET %s: ETag %s of the same schema built again, want %s", path, got.Header().Get("ETag"), etag)
+		}
+
+		for _, tc := range []struct {
+			ifNoneMatch string
+			want        int
+		}{
+			{etag, http.StatusNotModified},
+			{"W/" + etag, http.StatusNotModified},
+			{`"stale", ` + etag, http.StatusNotModified},
+			{`"stale"`, http.StatusOK},
+		} {
+			r := httptest.NewRequest(http.MethodGet, path, nil)
+			r.Header.Set("If-None-Match", tc.ifNoneMatch)
+			w := serve(h, r)
+			if w.Code != tc.want {
+				t.Errorf("GET %s with If-None-Match %s: status %d, want %d", path, tc.ifNoneMatch, w.Code, tc.want)
+			}
+			if tc.want == http.StatusNotModified && w.Body.Len() > 0 {
+				t.Errorf("GET %s with If-None-Match %s: 304 with a body", path, tc.ifNoneMatch)
+			}
+		}
+	}
+}
+
+func TestSchemaJSON(t *testing.T) {
+	h, err := serveSchemaJSON(newSchema(t, store.NewMemoryStore()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := serve(h, httptest.NewRequest(http.MethodGet, "/schema.json", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var res struct {
+		Data struct {
+			Schema struct {
+				QueryType struct{ Name string }
+				Types     []struct{ Name string }
+			} `json:"__schema"`
+		}
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if res.Data.Schema.QueryType.Name != "RootQuery" {
+		t.Errorf("queryType = %q, want RootQuery", res.Data.Schema.QueryType.Name)
+	}
+	types := res.Data.Schema.Types
+	for i := 1; i < len(types); i++ {
+		if types[i-1].Name > types[i].Name {
+			t.Fatalf("types are not sorted by name: %s before %s", types[i-1].Name, types[i].Name)
+		}
+	}
+}
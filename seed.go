@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// seedTodos are the demo todos written into an empty database.
+var seedTodos = []model.Todo{
+	{Text: "sdfsdf", Status: model.StatusTodo},
+}
+
+// seed inserts seedTodos for the anonymous user unless the store holds
+// todos of any user, so that restarting against a persistent database does
+// not duplicate them.
+func seed(ctx context.Context, s store.TodoStore) error {
+	return s.WithTx(ctx, func(tx store.TodoStore) error {
+		existing, err := tx.CountAll(ctx)
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			return nil
+		}
+
+		for _, todo := range seedTodos {
+			todo := todo
+			if err := tx.Create(ctx, &todo); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-xorm/xorm"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// newSQLiteStore returns an XormStore over a fresh in-memory database.
+func newSQLiteStore(t *testing.T) *store.XormStore {
+	t.Helper()
+
+	engine, err := xorm.NewEngine("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine.SetMaxOpenConns(1)
+
+	s, err := store.NewXormStore(engine)
+	if err != nil {
+		engine.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+// seedStores are the stores seed is tested against.
+func seedStores(t *testing.T) map[string]store.TodoStore {
+	return map[string]store.TodoStore{
+		"xorm":   newSQLiteStore(t),
+		"memory": store.NewMemoryStore(),
+	}
+}
+
+func TestSeedRunsOnce(t *testing.T) {
+	for name, s := range seedStores(t) {
+		ctx := context.Background()
+		for i := 0; i < 2; i++ {
+			if err := seed(ctx, s); err != nil {
+				t.Fatalf("%s: seed run %d: %v", name, i+1, err)
+			}
+		}
+
+		list, err := s.List(ctx, store.Sort{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != len(seedTodos) {
+			t.Errorf("%s: %d todos after seeding twice, want %d", name, len(list), len(seedTodos))
+		}
+	}
+}
+
+func TestSeedSkipsNonEmptyStore(t *testing.T) {
+	for name, s := range seedStores(t) {
+		ctx := context.Background()
+		// a todo of another user than the anonymous one seed writes as
+		if err := s.Create(store.WithUser(ctx, 42), &model.Todo{Text: "mine"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := seed(ctx, s); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		list, err := s.List(ctx, store.Sort{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 0 {
+			t.Errorf("%s: seed wrote %+v into a non-empty store", name, list)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package store_test
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+func TestLogSQL(t *testing.T) {
+	s := newXormStore(t)
+	var buf bytes.Buffer
+	s.LogSQL(log.New(&buf, "sql: ", 0))
+
+	ctx := context.Background()
+	if err := s.Create(ctx, &model.Todo{Text: "logged", Status: model.StatusTodo}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Get(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	logged := buf.String()
+	for _, want := range []string{"sql: ", "INSERT INTO", "SELECT", "logged"} {
+		if !strings.Contains(logged, want) {
+			t.Errorf("SQL log misses %q:\n%s", want, logged)
+		}
+	}
+}
@@ -0,0 +1,589 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// MemoryStore is a TodoStore that keeps todos in a map. It is handy for
+// tests and demos that should not touch sqlite.
+type MemoryStore struct {
+	mu          sync.Mutex
+	todos       map[int]model.Todo
+	nextID      int
+	transitions []model.StatusTransition
+	deps        []model.Dependency
+	tags        []model.Tag
+	todoTags    []model.TodoTag
+	rand        *rand.Rand
+	uniqueText  bool
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		todos:  make(map[int]model.Todo),
+		nextID: 1,
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// RequireUniqueText makes Create refuse a todo whose text another todo of
+// the same user already has.
+func (s *MemoryStore) RequireUniqueText() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.uniqueText = true
+	return nil
+}
+
+func (s *MemoryStore) Create(ctx context.Context, todo *model.Todo) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if todo.ClientMutationId != nil {
+		if _, ok := s.byClientMutationId(UserFrom(ctx), *todo.ClientMutationId); ok {
+			return errors.New("clientMutationId already used")
+		}
+	}
+	if s.uniqueText && s.textTaken(UserFrom(ctx), todo.Text, 0) {
+		return ErrDuplicateText
+	}
+
+	if todo.Id == 0 {
+		todo.Id = s.nextID
+	}
+	todo.UserId = UserFrom(ctx)
+	if todo.Created.IsZero() {
+		todo.Created = time.Now()
+	}
+	if todo.Priority == 0 {
+		todo.Priority = model.PriorityMedium
+	}
+	if todo.Position == 0 {
+		todo.Position = 1
+		for _, other := range s.todos {
+			if other.UserId == todo.UserId && other.Position >= todo.Position {
+				todo.Position = other.Position + 1
+			}
+		}
+	}
+	if todo.Id >= s.nextID {
+		s.nextID = todo.Id + 1
+	}
+	todo.Version = 1
+	s.todos[todo.Id] = *todo
+
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, id int) (*model.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, ok := s.todos[id]
+	if !ok || todo.UserId != UserFrom(ctx) {
+		return nil, ErrNotFound
+	}
+
+	return &todo, nil
+}
+
+func (s *MemoryStore) GetByClientMutationId(ctx context.Context, key string) (*model.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, ok := s.byClientMutationId(UserFrom(ctx), key)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return &todo, nil
+}
+
+// textTaken reports whether a todo of userID other than exceptID has text.
+// The caller holds mu.
+func (s *MemoryStore) textTaken(userID int64, text string, exceptID int) bool {
+	for _, other := range s.todos {
+		if other.UserId == userID && other.Id != exceptID && other.Text == text {
+			return true
+		}
+	}
+	return false
+}
+
+// byClientMutationId finds the todo of userID created with key. The caller
+// holds mu.
+func (s *MemoryStore) byClientMutationId(userID int64, key string) (model.Todo, bool) {
+	for _, todo := range s.todos {
+		if todo.UserId == userID && todo.ClientMutationId != nil && *todo.ClientMutationId == key {
+			return todo, true
+		}
+	}
+	return model.Todo{}, false
+}
+
+func (s *MemoryStore) GetMany(ctx context.Context, ids []int) ([]model.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := []model.Todo{}
+	seen := map[int]bool{}
+	userID := UserFrom(ctx)
+	for _, id := range ids {
+		if todo, ok := s.todos[id]; ok && todo.UserId == userID && !seen[id] {
+			all = append(all, todo)
+			seen[id] = true
+		}
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Id < all[j].Id })
+
+	return all, nil
+}
+
+func (s *MemoryStore) CountAll(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return int64(len(s.todos)), nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, sort Sort) ([]model.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	all := make([]model.Todo, 0, len(s.todos))
+	userID := UserFrom(ctx)
+	for _, todo := range s.todos {
+		if todo.UserId == userID {
+			all = append(all, todo)
+		}
+	}
+	if err := sortTodos(all, sort); err != nil {
+		return nil, err
+	}
+
+	return all, nil
+}
+
+func (s *MemoryStore) Each(ctx context.Context, fn func(todo model.Todo) error) error {
+	all, err := s.List(ctx, Sort{})
+	if err != nil {
+		return err
+	}
+
+	for _, todo := range all {
+		if err := fn(todo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) ListAfter(ctx context.Context, sort Sort, after *Keyset, limit int) ([]model.Todo, error) {
+	all, err := s.List(ctx, sort)
+	if err != nil {
+		return nil, err
+	}
+	if after == nil {
+		if len(all) > limit {
+			all = all[:limit]
+		}
+		return all, nil
+	}
+
+	sort, _ = sort.normalize()
+	key, err := sort.keysetTodo(*after)
+	if err != nil {
+		return nil, err
+	}
+
+	page := []model.Todo{}
+	for _, todo := range all {
+		if lessTodo(key, todo, sort) && len(page) < limit {
+			page = append(page, todo)
+		}
+	}
+
+	return page, nil
+}
+
+func (s *MemoryStore) Longest(ctx context.Context, limit int) ([]model.Todo, error) {
+	all, err := s.List(ctx, Sort{})
+	if err != nil {
+		return nil, err
+	}
+
+	// List is Id ordered, so a stable sort keeps ties by Id like the SQL does
+	sort.SliceStable(all, func(i, j int) bool {
+		return utf8.RuneCountInString(all[i].Text) > utf8.RuneCountInString(all[j].Text)
+	})
+	if len(all) > limit {
+		all = all[:limit]
+	}
+
+	return all, nil
+}
+
+func (s *MemoryStore) RandomActive(ctx context.Context) (*model.Todo, error) {
+	all, err := s.List(ctx, Sort{})
+	if err != nil {
+		return nil, err
+	}
+
+	var active []model.Todo
+	for _, todo := range all {
+		if todo.Status != model.StatusDone {
+			active = append(active, todo)
+		}
+	}
+	if len(active) == 0 {
+		return nil, ErrNotFound
+	}
+
+	s.mu.Lock()
+	todo := active[s.rand.Intn(len(active))]
+	s.mu.Unlock()
+
+	return &todo, nil
+}
+
+func (s *MemoryStore) Update(ctx context.Context, todo *model.Todo, cols ...string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, ok := s.todos[todo.Id]
+	if !ok || old.UserId != UserFrom(ctx) {
+		return ErrNotFound
+	}
+	writesText := len(cols) == 0
+	for _, col := range cols {
+		writesText = writesText || col == "text"
+	}
+	if s.uniqueText && writesText && s.textTaken(old.UserId, todo.Text, todo.Id) {
+		return ErrDuplicateText
+	}
+	if len(cols) == 0 {
+		todo.Version++
+		s.todos[todo.Id] = *todo
+		return nil
+	}
+
+	for _, col := range cols {
+		if err := copyColumn(&old, todo, col); err != nil {
+			return err
+		}
+	}
+	old.Version++
+	todo.Version = old.Version
+	s.todos[todo.Id] = old
+
+	return nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.todos[id]; !ok || old.UserId != UserFrom(ctx) {
+		return ErrNotFound
+	}
+	delete(s.todos, id)
+
+	deps := s.deps[:0]
+	for _, dep := range s.deps {
+		if dep.TodoId != id && dep.DependsOn != id {
+			deps = append(deps, dep)
+		}
+	}
+	s.deps = deps
+
+	todoTags := s.todoTags[:0]
+	for _, link := range s.todoTags {
+		if link.TodoId != id {
+			todoTags = append(todoTags, link)
+		}
+	}
+	s.todoTags = todoTags
+
+	return nil
+}
+
+func (s *MemoryStore) TruncateTexts(ctx context.Context, maxLen int) ([]model.Todo, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if maxLen < 1 {
+		return nil, fmt.Errorf("maxLen must be at least 1, got %d", maxLen)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var changed []model.Todo
+	for id, todo := range s.todos {
+		if text, ok := model.TruncateText(todo.Text, maxLen); ok {
+			todo.Text = text
+			todo.Version++
+			s.todos[id] = todo
+			changed = append(changed, todo)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Id < changed[j].Id })
+
+	return changed, nil
+}
+
+// IntegrityCheck has nothing to verify for a map and always reports ok.
+func (s *MemoryStore) IntegrityCheck(ctx context.Context) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return []string{"ok"}, nil
+}
+
+func (s *MemoryStore) AddTransition(ctx context.Context, tr *model.StatusTransition) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tr.Id = int64(len(s.transitions) + 1)
+	s.transitions = append(s.transitions, *tr)
+
+	return nil
+}
+
+func (s *MemoryStore) Transitions(ctx context.Context, todoID int) ([]model.StatusTransition, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var all []model.StatusTransition
+	for _, tr := range s.transitions {
+		if tr.TodoId == todoID {
+			all = append(all, tr)
+		}
+	}
+
+	return all, nil
+}
+
+func (s *MemoryStore) AddDependency(ctx context.Context, dep *model.Dependency) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dep.Id = 1
+	if n := len(s.deps); n > 0 {
+		dep.Id = s.deps[n-1].Id + 1
+	}
+	s.deps = append(s.deps, *dep)
+
+	return nil
+}
+
+func (s *MemoryStore) Dependencies(ctx context.Context) ([]model.Dependency, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var all []model.Dependency
+	userID := UserFrom(ctx)
+	for _, dep := range s.deps {
+		if todo, ok := s.todos[dep.TodoId]; ok && todo.UserId == userID {
+			all = append(all, dep)
+		}
+	}
+
+	return all, nil
+}
+
+func (s *MemoryStore) AddTag(ctx context.Context, todoID int, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var tag *model.Tag
+	for i := range s.tags {
+		if s.tags[i].Name == name {
+			tag = &s.tags[i]
+		}
+	}
+	if tag == nil {
+		s.tags = append(s.tags, model.Tag{Id: int64(len(s.tags) + 1), Name: name})
+		tag = &s.tags[len(s.tags)-1]
+	}
+
+	for _, link := range s.todoTags {
+		if link.TodoId == todoID && link.TagId == tag.Id {
+			return nil
+		}
+	}
+	link := model.TodoTag{Id: 1, TodoId: todoID, TagId: tag.Id}
+	if n := len(s.todoTags); n > 0 {
+		link.Id = s.todoTags[n-1].Id + 1
+	}
+	s.todoTags = append(s.todoTags, link)
+
+	return nil
+}
+
+func (s *MemoryStore) Tags(ctx context.Context) (map[int][]model.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var links []model.TodoTag
+	userID := UserFrom(ctx)
+	for _, link := range s.todoTags {
+		if todo, ok := s.todos[link.TodoId]; ok && todo.UserId == userID {
+			links = append(links, link)
+		}
+	}
+
+	tags := append([]model.Tag(nil), s.tags...)
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+
+	return groupTags(links, tags), nil
+}
+
+func (s *MemoryStore) SetTagColor(ctx context.Context, name, color string) (*model.Tag, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.tags {
+		if s.tags[i].Name == name {
+			s.tags[i].Color = color
+			tag := s.tags[i]
+			return &tag, nil
+		}
+	}
+
+	return nil, ErrTagNotFound
+}
+
+// WithTx runs fn against a copy of the store and swaps the copy in when fn
+// succeeds. The store stays locked meanwhile, so transactions are
+// serialized with every other call; fn must only use tx.
+func (s *MemoryStore) WithTx(ctx context.Context, fn func(tx TodoStore) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx := &MemoryStore{
+		todos:       make(map[int]model.Todo, len(s.todos)),
+		nextID:      s.nextID,
+		transitions: append([]model.StatusTransition(nil), s.transitions...),
+		deps:        append([]model.Dependency(nil), s.deps...),
+		tags:        append([]model.Tag(nil), s.tags...),
+		todoTags:    append([]model.TodoTag(nil), s.todoTags...),
+		rand:        s.rand,
+		uniqueText:  s.uniqueText,
+	}
+	for id, todo := range s.todos {
+		tx.todos[id] = todo
+	}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	s.todos = tx.todos
+	s.nextID = tx.nextID
+	s.transitions = tx.transitions
+	s.deps = tx.deps
+	s.tags = tx.tags
+	s.todoTags = tx.todoTags
+
+	return nil
+}
+
+// copyColumn copies the field behind col from src to dst.
+func copyColumn(dst, src *model.Todo, col string) error {
+	switch col {
+	case "text":
+		dst.Text = src.Text
+	case "done":
+		dst.Done = src.Done
+	case "status":
+		dst.Status = src.Status
+	case "due_date":
+		dst.DueDate = src.DueDate
+	case "estimate":
+		dst.Estimate = src.Estimate
+	case "priority":
+		dst.Priority = src.Priority
+	case "position":
+		dst.Position = src.Position
+	default:
+		return fmt.Errorf("cannot update column %q", col)
+	}
+	return nil
+}
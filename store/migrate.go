@@ -0,0 +1,105 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-xorm/xorm"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// migration is one schema change that Sync2 cannot express, such as a
+// backfill, rename or drop.
+type migration struct {
+	Version     int64
+	Description string
+	Up          func(session *xorm.Session) error
+}
+
+// migrations run in order of Version, each at most once per database.
+// Append new ones at the end and never change one that has shipped.
+var migrations = []migration{
+	{1, "backfill status from done", backfillStatus},
+	{2, "number positions by id", numberPositions},
+}
+
+// schemaMigration records a migration applied to the database.
+type schemaMigration struct {
+	Version     int64 `xorm:"pk"`
+	Description string
+	AppliedAt   time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrate applies the migrations the database has not seen yet, each in
+// its own transaction together with its schema_migrations row.
+func migrate(engine *xorm.Engine) error {
+	if err := engine.Sync2(new(schemaMigration)); err != nil {
+		return err
+	}
+
+	var applied []schemaMigration
+	if err := engine.Find(&applied); err != nil {
+		return err
+	}
+	done := make(map[int64]bool, len(applied))
+	for _, m := range applied {
+		done[m.Version] = true
+	}
+
+	for _, m := range migrations {
+		if done[m.Version] {
+			continue
+		}
+		if err := runMigration(engine, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %v", m.Version, m.Description, err)
+		}
+	}
+
+	return nil
+}
+
+func runMigration(engine *xorm.Engine, m migration) error {
+	session := engine.NewSession()
+	defer session.Close()
+
+	if err := session.Begin(); err != nil {
+		return err
+	}
+	if err := m.Up(session); err != nil {
+		session.Rollback()
+		return err
+	}
+	if _, err := session.Insert(&schemaMigration{
+		Version:     m.Version,
+		Description: m.Description,
+		AppliedAt:   time.Now(),
+	}); err != nil {
+		session.Rollback()
+		return err
+	}
+
+	return session.Commit()
+}
+
+// backfillStatus derives Status from Done for rows written before the
+// status column existed.
+func backfillStatus(session *xorm.Session) error {
+	if _, err := session.Exec("UPDATE todo SET status = ? WHERE done = ? AND (status IS NULL OR status = '')",
+		model.StatusDone, true); err != nil {
+		return err
+	}
+	_, err := session.Exec("UPDATE todo SET status = ? WHERE status IS NULL OR status = ''", model.StatusTodo)
+	return err
+}
+
+// numberPositions puts todos written before the position column existed
+// in Id order.
+func numberPositions(session *xorm.Session) error {
+	_, err := session.Exec("UPDATE todo SET position = id WHERE position IS NULL OR position = 0")
+	return err
+}
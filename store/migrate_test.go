@@ -0,0 +1,97 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/go-xorm/xorm"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// appliedVersions returns the versions schema_migrations records.
+func appliedVersions(t *testing.T, engine *xorm.Engine) []int64 {
+	t.Helper()
+
+	var applied []schemaMigration
+	if err := engine.Asc("version").Find(&applied); err != nil {
+		t.Fatal(err)
+	}
+	versions := []int64{}
+	for _, m := range applied {
+		versions = append(versions, m.Version)
+	}
+	return versions
+}
+
+func TestMigrateFreshDatabase(t *testing.T) {
+	engine, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "fresh.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+
+	if _, err := NewXormStore(engine); err != nil {
+		t.Fatal(err)
+	}
+
+	versions := appliedVersions(t, engine)
+	if len(versions) != len(migrations) {
+		t.Fatalf("applied %v, want every one of the %d migrations", versions, len(migrations))
+	}
+	for i, m := range migrations {
+		if versions[i] != m.Version {
+			t.Errorf("applied %v, want %d at %d", versions, m.Version, i)
+		}
+	}
+}
+
+func TestMigrateRunsOnce(t *testing.T) {
+	engine, err := xorm.NewEngine("sqlite3", filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer engine.Close()
+
+	// a table from before positions, holding rows the migration numbers
+	for _, sql := range []string{
+		"CREATE TABLE todo (id INTEGER PRIMARY KEY AUTOINCREMENT, user_id INTEGER, text TEXT, done INTEGER)",
+		"INSERT INTO todo (user_id, text, done) VALUES (0, 'first', 0), (0, 'second', 1)",
+	} {
+		if _, err := engine.Exec(sql); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := NewXormStore(engine); err != nil {
+		t.Fatalf("first run: %v", err)
+	}
+	var todos []model.Todo
+	if err := engine.Asc("id").Find(&todos); err != nil {
+		t.Fatal(err)
+	}
+	if len(todos) != 2 || todos[0].Position != 1 || todos[1].Position != 2 {
+		t.Fatalf("positions after the first run = %+v, want the Ids", todos)
+	}
+
+	// a second run must leave rows alone that a migration would touch
+	if _, err := engine.Exec("UPDATE todo SET position = 0 WHERE id = 2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewXormStore(engine); err != nil {
+		t.Fatalf("second run: %v", err)
+	}
+
+	todo := &model.Todo{}
+	if _, err := engine.ID(2).Get(todo); err != nil {
+		t.Fatal(err)
+	}
+	if todo.Position != 0 {
+		t.Errorf("position after the second run = %v, want it left at 0", todo.Position)
+	}
+	if versions := appliedVersions(t, engine); len(versions) != len(migrations) {
+		t.Errorf("applied %v after two runs, want each of the %d migrations once", versions, len(migrations))
+	}
+}
@@ -0,0 +1,36 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestListDefaultOrder(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.TodoStore) {
+		ctx := context.Background()
+		for _, id := range []int{7, 3, 9, 1, 5, 8, 2, 6, 4} {
+			if err := s.Create(ctx, &model.Todo{Id: id, Text: "todo", Status: model.StatusTodo}); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		all, err := s.List(ctx, store.Sort{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var list struct{ TodoList []todoJSON }
+		resolve(t, s, `{ todoList { Id } }`, &list)
+
+		if len(all) != 9 || len(list.TodoList) != 9 {
+			t.Fatalf("List returned %d todos and todoList %d, want 9", len(all), len(list.TodoList))
+		}
+		for i := range all {
+			if all[i].Id != i+1 || list.TodoList[i].Id != i+1 {
+				t.Fatalf("List = %+v, todoList = %+v, want Ids ascending", all, list.TodoList)
+			}
+		}
+	})
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// Retry is how XormStore retries a write that found the database locked:
+// up to Attempts more times, waiting Backoff before the first retry and
+// twice as long before each one after that.
+type Retry struct {
+	Attempts int
+	Backoff  time.Duration
+}
+
+// DefaultRetry copes with the short busy spells of sqlite under
+// concurrent writers.
+var DefaultRetry = Retry{Attempts: 3, Backoff: 10 * time.Millisecond}
+
+// do runs fn until it succeeds, fails for another reason than a locked
+// database, or the attempts run out, and returns its last error.
+func (r Retry) do(ctx context.Context, fn func() error) error {
+	delay := r.Backoff
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil || attempt >= r.Attempts || !isLocked(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// isLocked reports whether err is sqlite's SQLITE_BUSY. The store does not
+// import the driver, so it goes by the message.
+func isLocked(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "SQLITE_BUSY")
+}
@@ -0,0 +1,115 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-xorm/xorm"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+var errLocked = errors.New("database is locked")
+
+func TestRetryDo(t *testing.T) {
+	r := Retry{Attempts: 3, Backoff: time.Millisecond}
+	other := errors.New("no such table: todo")
+
+	for _, tc := range []struct {
+		name  string
+		fails []error
+		err   error
+		calls int
+	}{
+		{"succeeds at once", nil, nil, 1},
+		{"succeeds after two locks", []error{errLocked, errLocked}, nil, 3},
+		{"runs out of attempts", []error{errLocked, errLocked, errLocked, errLocked, errLocked}, errLocked, 4},
+		{"does not retry other errors", []error{other}, other, 1},
+	} {
+		calls := 0
+		err := r.do(context.Background(), func() error {
+			calls++
+			if calls <= len(tc.fails) {
+				return tc.fails[calls-1]
+			}
+			return nil
+		})
+		if err != tc.err || calls != tc.calls {
+			t.Errorf("%s: err = %v after %d calls, want %v after %d", tc.name, err, calls, tc.err, tc.calls)
+		}
+	}
+}
+
+func TestRetryStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := Retry{Attempts: 5, Backoff: time.Hour}.do(ctx, func() error {
+		calls++
+		return errLocked
+	})
+	if err != errLocked || calls != 1 {
+		t.Errorf("err = %v after %d calls, want the lock error after 1", err, calls)
+	}
+}
+
+// TestCreateRetriesLockedDatabase writes while another connection holds
+// the sqlite write lock, which it releases only after the first attempt
+// has failed.
+func TestCreateRetriesLockedDatabase(t *testing.T) {
+	// no busy timeout, so a locked database fails straight away
+	dsn := "file:" + filepath.Join(t.TempDir(), "locked.db") + "?_busy_timeout=0"
+	s, err := Open("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetRetry(Retry{Attempts: 8, Backoff: 10 * time.Millisecond})
+
+	holder, err := xorm.NewEngine("sqlite3", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Close()
+	session := holder.NewSession()
+	defer session.Close()
+	if err := session.Begin(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.Insert(&model.Todo{Text: "holding the lock"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// without retries the write fails on the lock
+	noRetry := &XormStore{engine: s.engine}
+	if err := noRetry.Create(context.Background(), &model.Todo{Text: "no retry"}); err == nil || !isLocked(err) {
+		t.Fatalf("Create without retries: err = %v, want a locked database", err)
+	}
+
+	released := make(chan error, 1)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		released <- session.Commit()
+	}()
+
+	if err := s.Create(context.Background(), &model.Todo{Text: "retried"}); err != nil {
+		t.Fatalf("Create with retries: %v", err)
+	}
+	if err := <-released; err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := s.CountAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 2 {
+		t.Errorf("%d todos, want the holder's and the retried one", n)
+	}
+}
@@ -0,0 +1,199 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// SortField is a todo attribute lists can be ordered by.
+type SortField string
+
+const (
+	SortByID       SortField = "ID"
+	SortByText     SortField = "TEXT"
+	SortByCreated  SortField = "CREATED_AT"
+	SortByPriority SortField = "PRIORITY"
+	SortByPosition SortField = "POSITION"
+)
+
+// SortOrder is the direction of a Sort.
+type SortOrder string
+
+const (
+	Asc  SortOrder = "ASC"
+	Desc SortOrder = "DESC"
+)
+
+// Sort orders a list of todos. The zero value sorts by Id ascending.
+type Sort struct {
+	Field SortField
+	Order SortOrder
+}
+
+// sortColumns is the allowlist of columns a Sort may translate to. Nothing
+// outside it ever reaches an ORDER BY clause.
+var sortColumns = map[SortField]string{
+	SortByID:       "id",
+	SortByText:     "text",
+	SortByCreated:  "created",
+	SortByPriority: "priority",
+	SortByPosition: "position",
+}
+
+func (s Sort) normalize() (Sort, error) {
+	if s.Field == "" {
+		s.Field = SortByID
+	}
+	if s.Order == "" {
+		s.Order = Asc
+	}
+
+	if _, ok := sortColumns[s.Field]; !ok {
+		return s, fmt.Errorf("cannot sort by %q", s.Field)
+	}
+	if s.Order != Asc && s.Order != Desc {
+		return s, fmt.Errorf("invalid sort order %q", s.Order)
+	}
+
+	return s, nil
+}
+
+// orderBy renders s as an ORDER BY clause, breaking ties by Id so the
+// order is always total.
+func (s Sort) orderBy() (string, error) {
+	s, err := s.normalize()
+	if err != nil {
+		return "", err
+	}
+
+	column := sortColumns[s.Field]
+	if column == "id" {
+		return "id " + string(s.Order), nil
+	}
+
+	return column + " " + string(s.Order) + ", id ASC", nil
+}
+
+// Keyset is the position of a row within a Sort: its value for the sort
+// field and its Id, which breaks ties.
+type Keyset struct {
+	Value interface{}
+	Id    int
+}
+
+// ValueOf returns the value of todo that s orders by.
+func (s Sort) ValueOf(todo model.Todo) interface{} {
+	switch s.Field {
+	case SortByText:
+		return todo.Text
+	case SortByCreated:
+		return todo.Created
+	case SortByPriority:
+		return todo.Priority
+	case SortByPosition:
+		return todo.Position
+	}
+	return todo.Id
+}
+
+// keysetWhere renders the condition selecting rows that sort after k.
+// The column comes from the allowlist; only placeholders carry values.
+func (s Sort) keysetWhere(k Keyset) (string, []interface{}, error) {
+	s, err := s.normalize()
+	if err != nil {
+		return "", nil, err
+	}
+
+	cmp := ">"
+	if s.Order == Desc {
+		cmp = "<"
+	}
+
+	column := sortColumns[s.Field]
+	if column == "id" {
+		return "id " + cmp + " ?", []interface{}{k.Id}, nil
+	}
+
+	return "(" + column + " " + cmp + " ? OR (" + column + " = ? AND id > ?))",
+		[]interface{}{k.Value, k.Value, k.Id}, nil
+}
+
+// keysetTodo builds a todo standing in for k so it can be compared with
+// lessTodo.
+func (s Sort) keysetTodo(k Keyset) (model.Todo, error) {
+	todo := model.Todo{Id: k.Id}
+
+	var ok bool
+	switch s.Field {
+	case SortByText:
+		todo.Text, ok = k.Value.(string)
+	case SortByCreated:
+		todo.Created, ok = k.Value.(time.Time)
+	case SortByPriority:
+		todo.Priority, ok = k.Value.(model.Priority)
+	case SortByPosition:
+		todo.Position, ok = k.Value.(float64)
+	default:
+		ok = true
+	}
+	if !ok {
+		return todo, fmt.Errorf("invalid %s keyset value %v", s.Field, k.Value)
+	}
+
+	return todo, nil
+}
+
+// lessTodo reports whether a comes before b the way orderBy sorts in SQL.
+// s must be normalized.
+func lessTodo(a, b model.Todo, s Sort) bool {
+	var cmp int
+	switch s.Field {
+	case SortByText:
+		cmp = strings.Compare(a.Text, b.Text)
+	case SortByCreated:
+		switch {
+		case a.Created.Before(b.Created):
+			cmp = -1
+		case a.Created.After(b.Created):
+			cmp = 1
+		}
+	case SortByPriority:
+		cmp = int(a.Priority) - int(b.Priority)
+	case SortByPosition:
+		switch {
+		case a.Position < b.Position:
+			cmp = -1
+		case a.Position > b.Position:
+			cmp = 1
+		}
+	}
+	if cmp == 0 {
+		if s.Field == SortByID && s.Order == Desc {
+			return a.Id > b.Id
+		}
+		return a.Id < b.Id
+	}
+
+	if s.Order == Desc {
+		return cmp > 0
+	}
+	return cmp < 0
+}
+
+// sortTodos orders todos in memory the same way orderBy does in SQL.
+func sortTodos(todos []model.Todo, s Sort) error {
+	s, err := s.normalize()
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(todos, func(i, j int) bool {
+		return lessTodo(todos[i], todos[j], s)
+	})
+
+	return nil
+}
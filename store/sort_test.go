@@ -0,0 +1,112 @@
+package store_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+var sortFields = []store.SortField{
+	store.SortByID,
+	store.SortByText,
+	store.SortByCreated,
+	store.SortByPriority,
+	store.SortByPosition,
+}
+
+// compareValues compares two values returned by Sort.ValueOf.
+func compareValues(a, b interface{}) int {
+	switch a := a.(type) {
+	case int:
+		return a - b.(int)
+	case string:
+		return strings.Compare(a, b.(string))
+	case model.Priority:
+		return int(a) - int(b.(model.Priority))
+	case float64:
+		switch b := b.(float64); {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+	case time.Time:
+		switch b := b.(time.Time); {
+		case a.Before(b):
+			return -1
+		case a.After(b):
+			return 1
+		}
+	}
+	return 0
+}
+
+// createSortable creates todos whose text, priority and position orders
+// all differ from their Id order, with some ties.
+func createSortable(t *testing.T, s store.TodoStore) {
+	t.Helper()
+
+	for _, todo := range []model.Todo{
+		{Text: "cherry", Priority: model.PriorityLow, Position: 3},
+		{Text: "apple", Priority: model.PriorityHigh, Position: 5},
+		{Text: "banana", Priority: model.PriorityMedium, Position: 1},
+		{Text: "apple", Priority: model.PriorityLow, Position: 4},
+		{Text: "date", Priority: model.PriorityHigh, Position: 2},
+	} {
+		todo := todo
+		if err := s.Create(context.Background(), &todo); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestListSort(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.TodoStore) {
+		createSortable(t, s)
+
+		for _, field := range sortFields {
+			for _, order := range []store.SortOrder{store.Asc, store.Desc} {
+				sort := store.Sort{Field: field, Order: order}
+				list, err := s.List(context.Background(), sort)
+				if err != nil {
+					t.Fatalf("%s %s: %v", field, order, err)
+				}
+				if len(list) != 5 {
+					t.Fatalf("%s %s: listed %d todos, want 5", field, order, len(list))
+				}
+
+				for i := 1; i < len(list); i++ {
+					a, b := list[i-1], list[i]
+					cmp := compareValues(sort.ValueOf(a), sort.ValueOf(b))
+					if order == store.Desc {
+						cmp = -cmp
+					}
+					tieOK := field != store.SortByID && cmp == 0 && a.Id < b.Id
+					if cmp >= 0 && !tieOK {
+						t.Errorf("%s %s: todo %d comes before todo %d", field, order, a.Id, b.Id)
+					}
+				}
+			}
+		}
+	})
+}
+
+func TestListSortRejectsUnknown(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.TodoStore) {
+		createSortable(t, s)
+
+		for _, sort := range []store.Sort{
+			{Field: "text; DROP TABLE todo"},
+			{Field: "user_id"},
+			{Field: store.SortByText, Order: "SIDEWAYS"},
+		} {
+			if _, err := s.List(context.Background(), sort); err == nil {
+				t.Errorf("List(%+v) succeeded, want an error", sort)
+			}
+		}
+	})
+}
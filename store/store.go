@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// ErrNotFound is returned when no todo matches the requested Id.
+var ErrNotFound = errors.New("todo not found")
+
+// ErrTagNotFound is returned when no tag has the requested name.
+var ErrTagNotFound = errors.New("tag not found")
+
+// ErrDuplicateText is returned by Create when unique texts are required
+// and the user already has a todo with the same text.
+var ErrDuplicateText = errors.New("a todo with this text already exists")
+
+// todoColumns are the columns of a todo that Update can write on their
+// own.
+var todoColumns = map[string]bool{
+	"text":     true,
+	"done":     true,
+	"status":   true,
+	"due_date": true,
+	"estimate": true,
+	"priority": true,
+	"position": true,
+}
+
+// IsDiskFull reports whether err means the database could not write
+// because its disk is full or failing. Drivers only tell by the message.
+func IsDiskFull(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "database or disk is full") || strings.Contains(msg, "disk I/O error")
+}
+
+// TodoStore is the persistence layer the resolvers talk to. Every method
+// honors cancellation and deadlines on ctx, and only sees the todos owned
+// by the user ctx is scoped to (see WithUser).
+type TodoStore interface {
+	// Create inserts a new todo owned by the context's user and fills in
+	// its generated Id.
+	Create(ctx context.Context, todo *model.Todo) error
+	// Get loads a todo by Id, returning ErrNotFound if there is none.
+	Get(ctx context.Context, id int) (*model.Todo, error)
+	// GetByClientMutationId loads the todo created with the given
+	// idempotency key, returning ErrNotFound if there is none.
+	GetByClientMutationId(ctx context.Context, key string) (*model.Todo, error)
+	// GetMany loads the todos with the given Ids in one go, ordered by Id.
+	// Ids without a todo are skipped.
+	GetMany(ctx context.Context, ids []int) ([]model.Todo, error)
+	// CountAll returns how many todos there are across all users.
+	CountAll(ctx context.Context) (int64, error)
+	// List returns every todo in the given order.
+	List(ctx context.Context, sort Sort) ([]model.Todo, error)
+	// Each calls fn with every todo in Id order, reading them one at a time
+	// so large tables need not fit in memory. It stops at the first error
+	// fn returns.
+	Each(ctx context.Context, fn func(todo model.Todo) error) error
+	// ListAfter returns up to limit todos in the given order, starting
+	// right after the after keyset, or from the beginning when it is nil.
+	ListAfter(ctx context.Context, sort Sort, after *Keyset, limit int) ([]model.Todo, error)
+	// Longest returns up to limit todos ordered by text length, longest
+	// first.
+	Longest(ctx context.Context, limit int) ([]model.Todo, error)
+	// RandomActive returns a random todo that is not done, or ErrNotFound
+	// when there is none.
+	RandomActive(ctx context.Context) (*model.Todo, error)
+	// Update writes todo back to its row. When cols are given only those
+	// columns are written, so fields the caller did not mean to change
+	// keep whatever is stored.
+	Update(ctx context.Context, todo *model.Todo, cols ...string) error
+	// Delete removes the todo with the given Id along with its
+	// dependencies and tags.
+	Delete(ctx context.Context, id int) error
+
+	// TruncateTexts shortens every todo text longer than maxLen, across all
+	// users, in a single transaction and returns the todos it changed.
+	// A maxLen below 1 is an error, as it would blank every text.
+	TruncateTexts(ctx context.Context, maxLen int) ([]model.Todo, error)
+
+	// IntegrityCheck asks the database to verify itself and returns the
+	// problems it reports, or just "ok" when there are none.
+	IntegrityCheck(ctx context.Context) ([]string, error)
+
+	// AddTransition records a status change of a todo.
+	AddTransition(ctx context.Context, tr *model.StatusTransition) error
+	// Transitions returns the status changes of a todo, oldest first.
+	Transitions(ctx context.Context, todoID int) ([]model.StatusTransition, error)
+
+	// AddDependency records that one todo depends on another. Callers
+	// check both todos exist and that no cycle is created.
+	AddDependency(ctx context.Context, dep *model.Dependency) error
+	// Dependencies returns every dependency between the todos of the
+	// context's user.
+	Dependencies(ctx context.Context) ([]model.Dependency, error)
+
+	// AddTag attaches the tag called name to a todo, creating the tag when
+	// no todo uses that name yet. Attaching a tag twice is a no-op.
+	AddTag(ctx context.Context, todoID int, name string) error
+	// Tags returns the tags of every todo of the context's user, keyed by
+	// todo Id and sorted by name.
+	Tags(ctx context.Context) (map[int][]model.Tag, error)
+	// SetTagColor sets the color of the tag called name, for every todo
+	// that carries it, and returns the updated tag.
+	SetTagColor(ctx context.Context, name, color string) (*model.Tag, error)
+
+	// WithTx runs fn against a store whose reads and writes form one
+	// transaction. It commits when fn returns nil and rolls back, leaving
+	// no partial writes, when fn returns an error. fn may be run again
+	// when the transaction has to be retried, so it should not keep
+	// state from a previous run.
+	WithTx(ctx context.Context, fn func(tx TodoStore) error) error
+}
@@ -0,0 +1,114 @@
+package store_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-xorm/xorm"
+	"github.com/graphql-go/graphql"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// newXormStore returns an XormStore over a fresh in-memory sqlite
+// database.
+func newXormStore(t *testing.T) *store.XormStore {
+	t.Helper()
+
+	engine, err := xorm.NewEngine("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection to :memory: opens a database of its own
+	engine.SetMaxOpenConns(1)
+
+	s, err := store.NewXormStore(engine)
+	if err != nil {
+		engine.Close()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	return s
+}
+
+// forEachStore runs test against an empty store of every implementation.
+func forEachStore(t *testing.T, test func(t *testing.T, s store.TodoStore)) {
+	t.Run("xorm", func(t *testing.T) { test(t, newXormStore(t)) })
+	t.Run("memory", func(t *testing.T) { test(t, store.NewMemoryStore()) })
+}
+
+// resolve runs query through a schema over s and decodes the data into v.
+func resolve(t *testing.T, s store.TodoStore, query string, v interface{}) {
+	t.Helper()
+
+	schema, err := graph.NewSchema(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: query,
+		Context:       graph.WithLoader(context.Background()),
+	})
+	if len(res.Errors) > 0 {
+		t.Fatalf("%s: %v", query, res.Errors)
+	}
+
+	b, err := json.Marshal(res.Data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		t.Fatalf("decoding %s: %v", b, err)
+	}
+}
+
+type todoJSON struct {
+	Id   int
+	Text string
+	Done bool
+}
+
+func TestResolvers(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.TodoStore) {
+		var created struct{ A, B todoJSON }
+		resolve(t, s, `mutation {
+			A: createTodo(Text: "first") { Id Text }
+			B: createTodo(Text: "second") { Id Text }
+		}`, &created)
+		if created.A.Text != "first" || created.B.Text != "second" || created.A.Id == created.B.Id {
+			t.Fatalf("createTodo = %+v", created)
+		}
+
+		var updated struct {
+			UpdateTodo struct{ Todo todoJSON }
+		}
+		resolve(t, s, `mutation { updateTodo(Id: 1, Done: true) { todo { Id Text Done } } }`, &updated)
+		if got := updated.UpdateTodo.Todo; !got.Done || got.Text != "first" {
+			t.Errorf("updateTodo = %+v", got)
+		}
+
+		var deleted struct{ DeleteTodo todoJSON }
+		resolve(t, s, `mutation { deleteTodo(Id: 2) { Id } }`, &deleted)
+		if deleted.DeleteTodo.Id != 2 {
+			t.Errorf("deleteTodo = %+v", deleted.DeleteTodo)
+		}
+
+		var list struct{ TodoList []todoJSON }
+		resolve(t, s, `{ todoList { Id Text Done } }`, &list)
+		if len(list.TodoList) != 1 || list.TodoList[0] != (todoJSON{Id: 1, Text: "first", Done: true}) {
+			t.Errorf("todoList = %+v, want only the updated first todo", list.TodoList)
+		}
+
+		var one struct{ Todo *todoJSON }
+		resolve(t, s, `{ todo(Id: 2) { Id } }`, &one)
+		if one.Todo != nil {
+			t.Errorf("todo(Id: 2) = %+v after deleting it", one.Todo)
+		}
+	})
+}
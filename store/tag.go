@@ -0,0 +1,28 @@
+package store
+
+import (
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// groupTags lists the tags linked to each todo, keeping the order of tags,
+// which the stores sort by name.
+func groupTags(links []model.TodoTag, tags []model.Tag) map[int][]model.Tag {
+	byTodo := map[int]map[int64]bool{}
+	for _, link := range links {
+		if byTodo[link.TodoId] == nil {
+			byTodo[link.TodoId] = map[int64]bool{}
+		}
+		byTodo[link.TodoId][link.TagId] = true
+	}
+
+	tagged := map[int][]model.Tag{}
+	for _, tag := range tags {
+		for todoID, tagIDs := range byTodo {
+			if tagIDs[tag.Id] {
+				tagged[todoID] = append(tagged[todoID], tag)
+			}
+		}
+	}
+
+	return tagged
+}
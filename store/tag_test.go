@@ -0,0 +1,50 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestSetTagColor(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.TodoStore) {
+		ctx := context.Background()
+		todo := &model.Todo{Text: "water the plants"}
+		if err := s.Create(ctx, todo); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"garden", "home"} {
+			if err := s.AddTag(ctx, todo.Id, name); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		tag, err := s.SetTagColor(ctx, "home", "#ff8800")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tag.Name != "home" || tag.Color != "#ff8800" {
+			t.Errorf("SetTagColor returned %+v", tag)
+		}
+
+		tags, err := s.Tags(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []model.Tag{{Name: "garden"}, {Name: "home", Color: "#ff8800"}}
+		if got := tags[todo.Id]; len(got) != len(want) {
+			t.Fatalf("tags = %+v, want %+v", got, want)
+		}
+		for i, tag := range tags[todo.Id] {
+			if tag.Name != want[i].Name || tag.Color != want[i].Color {
+				t.Errorf("tag %d = %+v, want %+v", i, tag, want[i])
+			}
+		}
+
+		if _, err := s.SetTagColor(ctx, "work", "#ff8800"); err != store.ErrTagNotFound {
+			t.Errorf("SetTagColor of a missing tag: %v, want %v", err, store.ErrTagNotFound)
+		}
+	})
+}
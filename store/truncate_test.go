@@ -0,0 +1,61 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestTruncateTexts(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.TodoStore) {
+		ctx := context.Background()
+		texts := []string{
+			"short",
+			"exactly 12 c",
+			"just over twelve",
+			"über-lange Überschrift",
+		}
+		for _, text := range texts {
+			if err := s.Create(ctx, &model.Todo{Text: text}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// a long todo of another user is shortened too
+		if err := s.Create(store.WithUser(ctx, 7), &model.Todo{Text: "someone else's long text"}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := s.TruncateTexts(ctx, 0); err == nil {
+			t.Error("TruncateTexts(0) did not fail")
+		}
+
+		changed, err := s.TruncateTexts(ctx, 12)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(changed) != 3 {
+			t.Fatalf("TruncateTexts changed %d todos, want 3: %+v", len(changed), changed)
+		}
+
+		list, err := s.List(ctx, store.Sort{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []string{"short", "exactly 12 c", "just over t…", "über-lange …"}
+		for i, todo := range list {
+			if todo.Text != want[i] {
+				t.Errorf("todo %d text = %q, want %q", todo.Id, todo.Text, want[i])
+			}
+		}
+
+		others, err := s.List(store.WithUser(ctx, 7), store.Sort{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(others) != 1 || others[0].Text != "someone els…" {
+			t.Errorf("other user's todos = %+v", others)
+		}
+	})
+}
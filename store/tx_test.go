@@ -0,0 +1,91 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestWithTxConcurrentToggles(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.TodoStore) {
+		ctx := context.Background()
+		todo := &model.Todo{Text: "toggle me"}
+		todo.SetStatus(model.StatusTodo)
+		if err := s.Create(ctx, todo); err != nil {
+			t.Fatal(err)
+		}
+
+		// an odd number of toggles must leave the todo done; a lost
+		// update would flip the outcome or fail the version check
+		const toggles = 21
+		var wg sync.WaitGroup
+		errs := make(chan error, toggles)
+		for i := 0; i < toggles; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- s.WithTx(ctx, func(tx store.TodoStore) error {
+					current, err := tx.Get(ctx, todo.Id)
+					if err != nil {
+						return err
+					}
+					current.SetDone(!current.Done)
+					return tx.Update(ctx, current, "done", "status")
+				})
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			if err != nil {
+				t.Error(err)
+			}
+		}
+
+		got, err := s.Get(ctx, todo.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Done || got.Status != model.StatusDone {
+			t.Errorf("after %d toggles the todo is %s done=%v, want DONE", toggles, got.Status, got.Done)
+		}
+	})
+}
+
+func TestWithTxRollback(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.TodoStore) {
+		ctx := context.Background()
+		todo := &model.Todo{Text: "before"}
+		if err := s.Create(ctx, todo); err != nil {
+			t.Fatal(err)
+		}
+
+		boom := errors.New("boom")
+		err := s.WithTx(ctx, func(tx store.TodoStore) error {
+			changed := *todo
+			changed.Text = "after"
+			if err := tx.Update(ctx, &changed, "text"); err != nil {
+				return err
+			}
+			if err := tx.Create(ctx, &model.Todo{Text: "never committed"}); err != nil {
+				return err
+			}
+			return boom
+		})
+		if err != boom {
+			t.Fatalf("WithTx = %v, want the error of fn", err)
+		}
+
+		list, err := s.List(ctx, store.Sort{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 1 || list[0].Text != "before" {
+			t.Errorf("after a rolled back transaction the store holds %+v", list)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestUniqueText(t *testing.T) {
+	for _, unique := range []bool{false, true} {
+		forEachStore(t, func(t *testing.T, s store.TodoStore) {
+			if unique {
+				if err := s.(interface{ RequireUniqueText() error }).RequireUniqueText(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			ctx := context.Background()
+			for _, text := range []string{"buy milk", "buy bread"} {
+				if err := s.Create(ctx, &model.Todo{Text: text, Status: model.StatusTodo}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want := error(nil)
+			if unique {
+				want = store.ErrDuplicateText
+			}
+
+			if err := s.Create(ctx, &model.Todo{Text: "buy milk", Status: model.StatusTodo}); err != want {
+				t.Errorf("unique=%v: Create of a repeated text: err = %v, want %v", unique, err, want)
+			}
+			bread, err := s.Get(ctx, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			bread.Text = "buy milk"
+			if err := s.Update(ctx, bread, "text"); err != want {
+				t.Errorf("unique=%v: Update to a repeated text: err = %v, want %v", unique, err, want)
+			}
+
+			// texts only have to be unique per user
+			other := store.WithUser(ctx, 2)
+			if err := s.Create(other, &model.Todo{Text: "buy milk", Status: model.StatusTodo}); err != nil {
+				t.Errorf("unique=%v: another user's Create: %v", unique, err)
+			}
+		})
+	}
+}
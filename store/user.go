@@ -0,0 +1,17 @@
+package store
+
+import "context"
+
+type userKey struct{}
+
+// WithUser returns a context whose store calls are scoped to userID.
+func WithUser(ctx context.Context, userID int64) context.Context {
+	return context.WithValue(ctx, userKey{}, userID)
+}
+
+// UserFrom returns the user a context is scoped to. Requests without an
+// identity share user 0.
+func UserFrom(ctx context.Context) int64 {
+	userID, _ := ctx.Value(userKey{}).(int64)
+	return userID
+}
@@ -0,0 +1,52 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestUserIsolation(t *testing.T) {
+	forEachStore(t, func(t *testing.T, s store.TodoStore) {
+		alice := store.WithUser(context.Background(), 1)
+		bob := store.WithUser(context.Background(), 2)
+
+		mine := &model.Todo{Text: "alice's todo"}
+		if err := s.Create(alice, mine); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Create(bob, &model.Todo{Text: "bob's todo"}); err != nil {
+			t.Fatal(err)
+		}
+
+		list, err := s.List(bob, store.Sort{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 1 || list[0].Text != "bob's todo" {
+			t.Errorf("bob lists %+v, want only his own todo", list)
+		}
+
+		if _, err := s.Get(bob, mine.Id); err != store.ErrNotFound {
+			t.Errorf("bob getting alice's todo: err = %v, want ErrNotFound", err)
+		}
+		stolen := *mine
+		stolen.Text = "taken over"
+		if err := s.Update(bob, &stolen, "text"); err != store.ErrNotFound {
+			t.Errorf("bob updating alice's todo: err = %v, want ErrNotFound", err)
+		}
+		if err := s.Delete(bob, mine.Id); err != store.ErrNotFound {
+			t.Errorf("bob deleting alice's todo: err = %v, want ErrNotFound", err)
+		}
+
+		got, err := s.Get(alice, mine.Id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Text != "alice's todo" {
+			t.Errorf("alice's todo is now %q", got.Text)
+		}
+	})
+}
@@ -0,0 +1,516 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/go-xorm/xorm"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/model"
+)
+
+// XormStore is a TodoStore backed by an xorm engine.
+type XormStore struct {
+	engine *xorm.Engine
+	// tx is the open transaction of a store handed out by WithTx.
+	tx *xorm.Session
+	// retry applies to writes outside of a transaction and to whole
+	// transactions.
+	retry Retry
+	// uniqueText makes Create refuse texts the user already has.
+	uniqueText bool
+}
+
+// Open creates an xorm engine for the given driver and data source and
+// makes sure the todo table exists.
+func Open(driverName, dataSourceName string) (*XormStore, error) {
+	engine, err := xorm.NewEngine(driverName, dataSourceName)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewXormStore(engine)
+}
+
+// NewXormStore wraps an existing engine, syncing the tables and running
+// pending migrations first.
+func NewXormStore(engine *xorm.Engine) (*XormStore, error) {
+	if err := engine.Sync2(new(model.Todo), new(model.StatusTransition), new(model.Dependency),
+		new(model.Tag), new(model.TodoTag)); err != nil {
+		return nil, err
+	}
+	if err := migrate(engine); err != nil {
+		return nil, err
+	}
+
+	return &XormStore{engine: engine, retry: DefaultRetry}, nil
+}
+
+// SetRetry changes how writes are retried when the database is locked.
+// Attempts of 0 turns retrying off.
+func (s *XormStore) SetRetry(r Retry) {
+	s.retry = r
+}
+
+// RequireUniqueText makes Create refuse a todo whose text another todo of
+// the same user already has. A unique index on user_id and text backs the
+// check against concurrent writers; it stays in place once created.
+func (s *XormStore) RequireUniqueText() error {
+	create := "CREATE UNIQUE INDEX IF NOT EXISTS UQE_todo_user_text ON todo (user_id, text)"
+	if s.engine.DriverName() == "mysql" {
+		create = "CREATE UNIQUE INDEX UQE_todo_user_text ON todo (user_id, text)"
+	}
+
+	if _, err := s.engine.Exec(create); err != nil && !strings.Contains(err.Error(), "Duplicate key name") {
+		return err
+	}
+	s.uniqueText = true
+
+	return nil
+}
+
+// duplicateText turns a violation of the index created by
+// RequireUniqueText into ErrDuplicateText. Drivers only tell by the
+// message: sqlite names the columns, PostgreSQL and MySQL the index.
+func duplicateText(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "UQE_todo_user_text") || strings.Contains(msg, "todo.user_id, todo.text") {
+		return ErrDuplicateText
+	}
+	return err
+}
+
+// LogSQL writes every statement the store runs, with its arguments and
+// how long it took, through logger's writer with its prefix and flags.
+func (s *XormStore) LogSQL(logger *log.Logger) {
+	s.engine.SetLogger(xorm.NewSimpleLogger2(logger.Writer(), logger.Prefix(), logger.Flags()))
+	s.engine.ShowSQL(true)
+	s.engine.ShowExecTime(true)
+}
+
+// Close releases the underlying engine.
+func (s *XormStore) Close() error {
+	return s.engine.Close()
+}
+
+// db starts a statement, inside the store's transaction if it has one.
+func (s *XormStore) db(ctx context.Context) *xorm.Session {
+	if s.tx != nil {
+		return s.tx.Context(ctx)
+	}
+	return s.engine.Context(ctx)
+}
+
+// scoped starts a statement limited to the todos of the context's user.
+func (s *XormStore) scoped(ctx context.Context) *xorm.Session {
+	return s.db(ctx).Where("user_id = ?", UserFrom(ctx))
+}
+
+// WithTx runs fn against a store bound to a single transaction, which is
+// committed when fn returns nil and rolled back otherwise. Calls on a
+// store already inside a transaction join it. When the database is locked
+// the whole transaction is retried, so fn may run more than once.
+func (s *XormStore) WithTx(ctx context.Context, fn func(tx TodoStore) error) error {
+	return s.withTx(ctx, func(tx *XormStore) error { return fn(tx) })
+}
+
+func (s *XormStore) withTx(ctx context.Context, fn func(tx *XormStore) error) error {
+	if s.tx != nil {
+		return fn(s)
+	}
+
+	return s.retry.do(ctx, func() error {
+		session := s.engine.NewSession()
+		defer session.Close()
+
+		if err := session.Context(ctx).Begin(); err != nil {
+			return err
+		}
+		if err := fn(&XormStore{engine: s.engine, tx: session, uniqueText: s.uniqueText}); err != nil {
+			session.Rollback()
+			return err
+		}
+
+		return session.Commit()
+	})
+}
+
+// write runs a single statement, retrying it when the database is locked.
+// Inside a transaction the retry is left to the transaction as a whole.
+func (s *XormStore) write(ctx context.Context, fn func() error) error {
+	if s.tx != nil {
+		return fn()
+	}
+	return s.retry.do(ctx, fn)
+}
+
+func (s *XormStore) Create(ctx context.Context, todo *model.Todo) error {
+	todo.UserId = UserFrom(ctx)
+	if todo.Priority == 0 {
+		todo.Priority = model.PriorityMedium
+	}
+	if todo.Position == 0 {
+		last := &model.Todo{}
+		if _, err := s.scoped(ctx).Desc("position").Get(last); err != nil {
+			return err
+		}
+		todo.Position = last.Position + 1
+	}
+	if s.uniqueText {
+		return s.withTx(ctx, func(tx *XormStore) error {
+			taken, err := tx.scoped(ctx).And("text = ?", todo.Text).Exist(&model.Todo{})
+			if err != nil {
+				return err
+			}
+			if taken {
+				return ErrDuplicateText
+			}
+
+			_, err = tx.db(ctx).Insert(todo)
+			return duplicateText(err)
+		})
+	}
+
+	return s.write(ctx, func() error {
+		_, err := s.db(ctx).Insert(todo)
+		return duplicateText(err)
+	})
+}
+
+func (s *XormStore) Get(ctx context.Context, id int) (*model.Todo, error) {
+	todo := &model.Todo{}
+	has, err := s.scoped(ctx).ID(id).Get(todo)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrNotFound
+	}
+
+	return todo, nil
+}
+
+func (s *XormStore) GetByClientMutationId(ctx context.Context, key string) (*model.Todo, error) {
+	todo := &model.Todo{}
+	has, err := s.scoped(ctx).And("client_mutation_id = ?", key).Get(todo)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrNotFound
+	}
+
+	return todo, nil
+}
+
+func (s *XormStore) GetMany(ctx context.Context, ids []int) ([]model.Todo, error) {
+	all := []model.Todo{}
+	if len(ids) == 0 {
+		return all, nil
+	}
+
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	err := s.scoped(ctx).In("id", args...).Asc("id").Find(&all)
+	return all, err
+}
+
+func (s *XormStore) CountAll(ctx context.Context) (int64, error) {
+	return s.db(ctx).Count(&model.Todo{})
+}
+
+func (s *XormStore) List(ctx context.Context, sort Sort) ([]model.Todo, error) {
+	orderBy, err := sort.orderBy()
+	if err != nil {
+		return nil, err
+	}
+
+	var all []model.Todo
+	err = s.scoped(ctx).OrderBy(orderBy).Find(&all)
+	return all, err
+}
+
+func (s *XormStore) Each(ctx context.Context, fn func(todo model.Todo) error) error {
+	rows, err := s.scoped(ctx).Asc("id").Rows(&model.Todo{})
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todo model.Todo
+		if err := rows.Scan(&todo); err != nil {
+			return err
+		}
+		if err := fn(todo); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (s *XormStore) ListAfter(ctx context.Context, sort Sort, after *Keyset, limit int) ([]model.Todo, error) {
+	orderBy, err := sort.orderBy()
+	if err != nil {
+		return nil, err
+	}
+
+	session := s.scoped(ctx)
+	if after != nil {
+		where, args, err := sort.keysetWhere(*after)
+		if err != nil {
+			return nil, err
+		}
+		for i, arg := range args {
+			args[i] = s.sqlValue(arg)
+		}
+		session = session.And(where, args...)
+	}
+
+	var all []model.Todo
+	err = session.OrderBy(orderBy).Limit(limit).Find(&all)
+	return all, err
+}
+
+// sqlValue formats times the way xorm stores them so raw comparisons
+// against time columns line up.
+func (s *XormStore) sqlValue(v interface{}) interface{} {
+	if t, ok := v.(time.Time); ok {
+		return t.In(s.engine.TZLocation).Format("2006-01-02 15:04:05")
+	}
+	return v
+}
+
+func (s *XormStore) Longest(ctx context.Context, limit int) ([]model.Todo, error) {
+	var all []model.Todo
+	err := s.scoped(ctx).OrderBy("LENGTH(text) DESC, id ASC").Limit(limit).Find(&all)
+	return all, err
+}
+
+func (s *XormStore) RandomActive(ctx context.Context) (*model.Todo, error) {
+	random := "RANDOM()"
+	if s.engine.DriverName() == "mysql" {
+		random = "RAND()"
+	}
+
+	todo := &model.Todo{}
+	has, err := s.scoped(ctx).And("status <> ?", model.StatusDone).OrderBy(random).Limit(1).Get(todo)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrNotFound
+	}
+
+	return todo, nil
+}
+
+func (s *XormStore) Update(ctx context.Context, todo *model.Todo, cols ...string) error {
+	for _, col := range cols {
+		if !todoColumns[col] {
+			return fmt.Errorf("cannot update column %q", col)
+		}
+	}
+
+	return s.write(ctx, func() error {
+		session := s.scoped(ctx).ID(todo.Id)
+		if len(cols) > 0 {
+			session = session.Cols(cols...)
+		} else {
+			session = session.AllCols()
+		}
+
+		affected, err := session.Update(todo)
+		if err != nil {
+			return duplicateText(err)
+		}
+		if affected == 0 {
+			return ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+func (s *XormStore) Delete(ctx context.Context, id int) error {
+	return s.withTx(ctx, func(tx *XormStore) error {
+		affected, err := tx.scoped(ctx).ID(id).Delete(&model.Todo{})
+		if err != nil {
+			return err
+		}
+		if affected == 0 {
+			return ErrNotFound
+		}
+
+		if _, err := tx.db(ctx).Where("todo_id = ? OR depends_on = ?", id, id).Delete(&model.Dependency{}); err != nil {
+			return err
+		}
+		_, err = tx.db(ctx).Where("todo_id = ?", id).Delete(&model.TodoTag{})
+		return err
+	})
+}
+
+func (s *XormStore) TruncateTexts(ctx context.Context, maxLen int) ([]model.Todo, error) {
+	if maxLen < 1 {
+		return nil, fmt.Errorf("maxLen must be at least 1, got %d", maxLen)
+	}
+
+	var changed []model.Todo
+	err := s.withTx(ctx, func(tx *XormStore) error {
+		changed = nil
+		var long []model.Todo
+		if err := tx.db(ctx).Where("LENGTH(text) > ?", maxLen).Find(&long); err != nil {
+			return err
+		}
+
+		for i := range long {
+			text, ok := model.TruncateText(long[i].Text, maxLen)
+			if !ok {
+				continue
+			}
+
+			long[i].Text = text
+			if _, err := tx.db(ctx).ID(long[i].Id).Cols("text").Update(&long[i]); err != nil {
+				return err
+			}
+			changed = append(changed, long[i])
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return changed, nil
+}
+
+func (s *XormStore) IntegrityCheck(ctx context.Context) ([]string, error) {
+	if s.engine.DriverName() != "sqlite3" {
+		return nil, fmt.Errorf("integrity check is not supported on %s", s.engine.DriverName())
+	}
+
+	rows, err := s.db(ctx).QueryString("PRAGMA integrity_check")
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]string, 0, len(rows))
+	for _, row := range rows {
+		results = append(results, row["integrity_check"])
+	}
+
+	return results, nil
+}
+
+func (s *XormStore) AddTransition(ctx context.Context, tr *model.StatusTransition) error {
+	return s.write(ctx, func() error {
+		_, err := s.db(ctx).Insert(tr)
+		return err
+	})
+}
+
+func (s *XormStore) Transitions(ctx context.Context, todoID int) ([]model.StatusTransition, error) {
+	var all []model.StatusTransition
+	err := s.db(ctx).Where("todo_id = ?", todoID).Asc("id").Find(&all)
+	return all, err
+}
+
+func (s *XormStore) AddDependency(ctx context.Context, dep *model.Dependency) error {
+	return s.write(ctx, func() error {
+		_, err := s.db(ctx).Insert(dep)
+		return err
+	})
+}
+
+func (s *XormStore) Dependencies(ctx context.Context) ([]model.Dependency, error) {
+	var all []model.Dependency
+	err := s.db(ctx).
+		Join("INNER", "todo", "todo.id = dependency.todo_id").
+		Where("todo.user_id = ?", UserFrom(ctx)).
+		Asc("dependency.id").
+		Find(&all)
+	return all, err
+}
+
+func (s *XormStore) AddTag(ctx context.Context, todoID int, name string) error {
+	return s.withTx(ctx, func(tx *XormStore) error {
+		tag := &model.Tag{}
+		has, err := tx.db(ctx).Where("name = ?", name).Get(tag)
+		if err != nil {
+			return err
+		}
+		if !has {
+			tag.Name = name
+			if _, err := tx.db(ctx).Insert(tag); err != nil {
+				return err
+			}
+		}
+
+		linked, err := tx.db(ctx).Where("todo_id = ? AND tag_id = ?", todoID, tag.Id).Exist(&model.TodoTag{})
+		if err != nil || linked {
+			return err
+		}
+
+		_, err = tx.db(ctx).Insert(&model.TodoTag{TodoId: todoID, TagId: tag.Id})
+		return err
+	})
+}
+
+func (s *XormStore) Tags(ctx context.Context) (map[int][]model.Tag, error) {
+	var links []model.TodoTag
+	err := s.db(ctx).
+		Join("INNER", "todo", "todo.id = todo_tag.todo_id").
+		Where("todo.user_id = ?", UserFrom(ctx)).
+		Find(&links)
+	if err != nil {
+		return nil, err
+	}
+
+	tagged := map[int][]model.Tag{}
+	if len(links) == 0 {
+		return tagged, nil
+	}
+
+	ids := make([]interface{}, len(links))
+	for i, link := range links {
+		ids[i] = link.TagId
+	}
+	var tags []model.Tag
+	if err := s.db(ctx).In("id", ids...).Asc("name").Find(&tags); err != nil {
+		return nil, err
+	}
+
+	return groupTags(links, tags), nil
+}
+
+func (s *XormStore) SetTagColor(ctx context.Context, name, color string) (*model.Tag, error) {
+	tag := &model.Tag{}
+	err := s.withTx(ctx, func(tx *XormStore) error {
+		has, err := tx.db(ctx).Where("name = ?", name).Get(tag)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return ErrTagNotFound
+		}
+
+		tag.Color = color
+		_, err = tx.db(ctx).ID(tag.Id).Cols("color").Update(tag)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tag, nil
+}
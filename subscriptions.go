@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/pubsub"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// graphql-ws (subscriptions-transport-ws) message types
+const (
+	gqlConnectionInit      = "connection_init"
+	gqlConnectionAck       = "connection_ack"
+	gqlConnectionTerminate = "connection_terminate"
+	gqlStart               = "start"
+	gqlStop                = "stop"
+	gqlData                = "data"
+	gqlError               = "error"
+	gqlComplete            = "complete"
+)
+
+// subscriptionBuffer is how many events may queue for one subscription
+// before it starts missing them.
+const subscriptionBuffer = 16
+
+type wsMessage struct {
+	Id      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+var upgrader = websocket.Upgrader{
+	Subprotocols: []string{"graphql-ws"},
+}
+
+// serveSubscriptions speaks the graphql-ws protocol over a WebSocket. Each
+// started subscription is executed against s once per event published to
+// broker, for events on todos of the connection's user. Queries arrive
+// after the handshake, out of reach of withDepthLimit, so start applies
+// maxDepth itself; 0 disables it. Connections beyond what conns allows
+// are closed right after the upgrade, with a close frame saying why, so
+// that graphql-ws clients see the reason rather than a failed handshake.
+func serveSubscriptions(s graphql.Schema, broker *pubsub.Broker, maxDepth int, conns *connLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade already answered with an HTTP error
+			return
+		}
+		defer conn.Close()
+
+		var client string
+		if conns != nil {
+			client = clientIP(r, conns.trustProxy)
+		}
+		if err := conns.acquire(client); err != nil {
+			closeConn(conn, websocket.CloseTryAgainLater, err.Error())
+			return
+		}
+		defer conns.release(client)
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		sc := &subscriptionConn{
+			conn:     conn,
+			schema:   s,
+			broker:   broker,
+			maxDepth: maxDepth,
+			ops:      make(map[string]func()),
+		}
+		defer sc.stopAll()
+
+		for {
+			var msg wsMessage
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+
+			switch msg.Type {
+			case gqlConnectionInit:
+				sc.write(wsMessage{Type: gqlConnectionAck})
+			case gqlStart:
+				sc.start(ctx, msg)
+			case gqlStop:
+				sc.stop(msg.Id)
+			case gqlConnectionTerminate:
+				return
+			default:
+				sc.writeError(msg.Id, "unknown message type "+msg.Type)
+			}
+		}
+	}
+}
+
+// closeConn sends a close frame with code and reason, giving the client a
+// moment to receive it before the connection goes away.
+func closeConn(conn *websocket.Conn, code int, reason string) {
+	msg := websocket.FormatCloseMessage(code, reason)
+	conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+}
+
+// subscriptionConn tracks the running operations of one connection.
+type subscriptionConn struct {
+	conn     *websocket.Conn
+	schema   graphql.Schema
+	broker   *pubsub.Broker
+	maxDepth int
+
+	writeMu sync.Mutex
+
+	opsMu sync.Mutex
+	ops   map[string]func()
+}
+
+func (c *subscriptionConn) start(ctx context.Context, msg wsMessage) {
+	var payload graphQLRequest
+	if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+		c.writeError(msg.Id, err.Error())
+		return
+	}
+	if err := checkSubscription(payload.Query, payload.OperationName); err != nil {
+		c.writeError(msg.Id, err.Error())
+		return
+	}
+	if depth := queryDepth(payload.Query); c.maxDepth > 0 && depth > c.maxDepth {
+		c.writeError(msg.Id, fmt.Sprintf("query depth %d exceeds the maximum of %d", depth, c.maxDepth))
+		return
+	}
+
+	events, unsubscribe := c.broker.Subscribe(subscriptionBuffer)
+
+	c.opsMu.Lock()
+	if _, ok := c.ops[msg.Id]; ok {
+		c.opsMu.Unlock()
+		unsubscribe()
+		c.writeError(msg.Id, "subscription "+msg.Id+" is already running")
+		return
+	}
+	c.ops[msg.Id] = unsubscribe
+	c.opsMu.Unlock()
+
+	userID := store.UserFrom(ctx)
+	go func() {
+		for event := range events {
+			if event.Todo.UserId != userID {
+				continue
+			}
+
+			res := graphql.Do(graphql.Params{
+				Schema:         c.schema,
+				RequestString:  payload.Query,
+				VariableValues: payload.Variables,
+				OperationName:  payload.OperationName,
+				RootObject:     map[string]interface{}{graph.EventKey: event},
+				Context:        graph.WithLoader(ctx),
+			})
+			data, err := json.Marshal(res)
+			if err != nil {
+				c.writeError(msg.Id, err.Error())
+				continue
+			}
+			c.write(wsMessage{Id: msg.Id, Type: gqlData, Payload: data})
+		}
+	}()
+}
+
+func (c *subscriptionConn) stop(id string) {
+	c.opsMu.Lock()
+	unsubscribe, ok := c.ops[id]
+	delete(c.ops, id)
+	c.opsMu.Unlock()
+
+	if ok {
+		unsubscribe()
+		c.write(wsMessage{Id: id, Type: gqlComplete})
+	}
+}
+
+func (c *subscriptionConn) stopAll() {
+	c.opsMu.Lock()
+	defer c.opsMu.Unlock()
+
+	for id, unsubscribe := range c.ops {
+		unsubscribe()
+		delete(c.ops, id)
+	}
+}
+
+func (c *subscriptionConn) write(msg wsMessage) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.conn.WriteJSON(msg)
+}
+
+func (c *subscriptionConn) writeError(id, message string) {
+	payload, _ := json.Marshal(map[string]string{"message": message})
+	c.write(wsMessage{Id: id, Type: gqlError, Payload: payload})
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/graphql-go/graphql"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/pubsub"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// readMessage reads the next graphql-ws message, failing on anything but
+// the want type.
+func readMessage(t *testing.T, conn *websocket.Conn, want string) wsMessage {
+	t.Helper()
+
+	var msg wsMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Type != want {
+		t.Fatalf("got a %s message %s, want %s", msg.Type, msg.Payload, want)
+	}
+	return msg
+}
+
+func TestSubscriptionReceivesCreate(t *testing.T) {
+	broker := pubsub.NewBroker()
+	schema, err := graph.NewSchema(store.NewMemoryStore(), graph.WithBroker(broker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(serveSubscriptions(schema, broker, 0, nil))
+	defer srv.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-ws"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	conn.WriteJSON(wsMessage{Type: gqlConnectionInit})
+	readMessage(t, conn, gqlConnectionAck)
+
+	payload, _ := json.Marshal(graphQLRequest{Query: `subscription { todoChanged { type todo { Text } } }`})
+	conn.WriteJSON(wsMessage{Id: "1", Type: gqlStart, Payload: payload})
+	// messages are handled in order, so once this is answered the
+	// subscription is listening
+	conn.WriteJSON(wsMessage{Type: "sync"})
+	readMessage(t, conn, gqlError)
+
+	res := graphql.Do(graphql.Params{
+		Schema:        schema,
+		RequestString: `mutation { createTodo(Text: "pushed") { Id } }`,
+		Context:       graph.WithLoader(context.Background()),
+	})
+	if res.HasErrors() {
+		t.Fatal(res.Errors)
+	}
+
+	msg := readMessage(t, conn, gqlData)
+	var data struct {
+		Data struct {
+			TodoChanged struct {
+				Type string
+				Todo struct{ Text string }
+			}
+		}
+	}
+	if err := json.Unmarshal(msg.Payload, &data); err != nil {
+		t.Fatal(err)
+	}
+	if got := data.Data.TodoChanged; msg.Id != "1" || got.Type != "CREATED" || got.Todo.Text != "pushed" {
+		t.Errorf("subscription %s received %s", msg.Id, msg.Payload)
+	}
+}
+
+func TestSubscriptionRefusesOtherOperations(t *testing.T) {
+	broker := pubsub.NewBroker()
+	s := store.NewMemoryStore()
+	schema, err := graph.NewSchema(s, graph.WithBroker(broker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(serveSubscriptions(schema, broker, 0, nil))
+	defer srv.Close()
+
+	create := func(text string) {
+		t.Helper()
+		res := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  `mutation($text: String!) { createTodo(Text: $text) { Id } }`,
+			VariableValues: map[string]interface{}{"text": text},
+			Context:        graph.WithLoader(context.Background()),
+		})
+		if res.HasErrors() {
+			t.Fatal(res.Errors)
+		}
+	}
+	create("keep me")
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-ws"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	conn.WriteJSON(wsMessage{Type: gqlConnectionInit})
+	readMessage(t, conn, gqlConnectionAck)
+
+	mixed := `subscription a { todoChanged { type } } mutation b { deleteTodo(Id: 1) { Id } }`
+	for _, req := range []graphQLRequest{
+		{Query: mixed, OperationName: "b"},
+		{Query: mixed, OperationName: "a"},
+		{Query: mixed},
+		{Query: `query { todos { Id } }`},
+		{Query: `subscription a { todoChanged { type } }`, OperationName: "c"},
+	} {
+		payload, _ := json.Marshal(req)
+		conn.WriteJSON(wsMessage{Id: "1", Type: gqlStart, Payload: payload})
+		if msg := readMessage(t, conn, gqlError); msg.Id != "1" {
+			t.Errorf("%q as %q: error for operation %q, want 1", req.Query, req.OperationName, msg.Id)
+		}
+	}
+
+	payload, _ := json.Marshal(graphQLRequest{Query: `subscription { todoChanged { type } }`})
+	conn.WriteJSON(wsMessage{Id: "2", Type: gqlStart, Payload: payload})
+	conn.WriteJSON(wsMessage{Type: "sync"})
+	readMessage(t, conn, gqlError)
+
+	create("pushed")
+	if msg := readMessage(t, conn, gqlData); msg.Id != "2" {
+		t.Errorf("data for operation %q, want 2", msg.Id)
+	}
+	if _, err := s.Get(context.Background(), 1); err != nil {
+		t.Errorf("todo 1 is gone after an event: %v", err)
+	}
+}
+
+func TestSubscriptionDepthLimit(t *testing.T) {
+	broker := pubsub.NewBroker()
+	schema, err := graph.NewSchema(store.NewMemoryStore(), graph.WithBroker(broker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(serveSubscriptions(schema, broker, 2, nil))
+	defer srv.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-ws"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	conn.WriteJSON(wsMessage{Type: gqlConnectionInit})
+	readMessage(t, conn, gqlConnectionAck)
+
+	payload, _ := json.Marshal(graphQLRequest{Query: `subscription { todoChanged { todo { Tags { Name } } } }`})
+	conn.WriteJSON(wsMessage{Id: "1", Type: gqlStart, Payload: payload})
+	msg := readMessage(t, conn, gqlError)
+	if !strings.Contains(string(msg.Payload), "exceeds the maximum of 2") {
+		t.Errorf("got %s, want the depth error", msg.Payload)
+	}
+}
+
+// dialSubscriptions opens a graphql-ws connection to srv and sends
+// connection_init, leaving the answer to the caller.
+func dialSubscriptions(t *testing.T, srv *httptest.Server) *websocket.Conn {
+	t.Helper()
+
+	dialer := websocket.Dialer{Subprotocols: []string{"graphql-ws"}}
+	conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	conn.WriteJSON(wsMessage{Type: gqlConnectionInit})
+	return conn
+}
+
+// readClose expects the server to close conn with code.
+func readClose(t *testing.T, conn *websocket.Conn, code int) {
+	t.Helper()
+
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, code) {
+		t.Fatalf("got %v, want a close with code %d", err, code)
+	}
+}
+
+func TestSubscriptionConnectionLimit(t *testing.T) {
+	broker := pubsub.NewBroker()
+	schema, err := graph.NewSchema(store.NewMemoryStore(), graph.WithBroker(broker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(serveSubscriptions(schema, broker, 0, newConnLimiter(2, 0, false)))
+	defer srv.Close()
+
+	var open []*websocket.Conn
+	for i := 0; i < 2; i++ {
+		conn := dialSubscriptions(t, srv)
+		defer conn.Close()
+		readMessage(t, conn, gqlConnectionAck)
+		open = append(open, conn)
+	}
+
+	over := dialSubscriptions(t, srv)
+	defer over.Close()
+	readClose(t, over, websocket.CloseTryAgainLater)
+
+	// the slot frees up once the server notices the disconnect
+	open[0].Close()
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		conn := dialSubscriptions(t, srv)
+		var msg wsMessage
+		err := conn.ReadJSON(&msg)
+		conn.Close()
+		if err == nil && msg.Type == gqlConnectionAck {
+			break
+		}
+		if !websocket.IsCloseError(err, websocket.CloseTryAgainLater) || time.Now().After(deadline) {
+			t.Fatalf("connection after a disconnect: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSubscriptionClientLimit(t *testing.T) {
+	broker := pubsub.NewBroker()
+	schema, err := graph.NewSchema(store.NewMemoryStore(), graph.WithBroker(broker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(serveSubscriptions(schema, broker, 0, newConnLimiter(0, 2, true)))
+	defer srv.Close()
+
+	dial := func(ip string) *websocket.Conn {
+		t.Helper()
+		dialer := websocket.Dialer{Subprotocols: []string{"graphql-ws"}}
+		conn, _, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), http.Header{"X-Forwarded-For": {ip}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		conn.WriteJSON(wsMessage{Type: gqlConnectionInit})
+		return conn
+	}
+
+	for i := 0; i < 2; i++ {
+		conn := dial("192.0.2.1")
+		defer conn.Close()
+		readMessage(t, conn, gqlConnectionAck)
+	}
+
+	over := dial("192.0.2.1")
+	defer over.Close()
+	readClose(t, over, websocket.CloseTryAgainLater)
+
+	// another client still gets in
+	other := dial("192.0.2.2")
+	defer other.Close()
+	readMessage(t, other, gqlConnectionAck)
+}
+
+func TestRESTPublishes(t *testing.T) {
+	broker := pubsub.NewBroker()
+	events, unsubscribe := broker.Subscribe(3)
+	defer unsubscribe()
+	h := serveREST(store.NewMemoryStore(), broker)
+
+	restCall(h, http.MethodPost, "/api/todos", `{"Text": "a"}`)
+	restCall(h, http.MethodPatch, "/api/todos/1", `{"Done": true}`)
+	restCall(h, http.MethodDelete, "/api/todos/1", "")
+
+	for _, want := range []pubsub.ChangeType{pubsub.Created, pubsub.Updated, pubsub.Deleted} {
+		select {
+		case e := <-events:
+			if e.Type != want || e.Todo.Id != 1 {
+				t.Errorf("got %s of todo %d, want %s of todo 1", e.Type, e.Todo.Id, want)
+			}
+		default:
+			t.Fatalf("no %s event published", want)
+		}
+	}
+}
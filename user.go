@@ -0,0 +1,29 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+// withUser scopes the request to the user named in the X-User-Id header.
+// It is meant to sit behind an authenticating proxy that sets the header;
+// requests without it act as the shared anonymous user.
+func withUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := r.Header.Get("X-User-Id")
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		userID, err := strconv.ParseInt(header, 10, 64)
+		if err != nil || userID <= 0 {
+			writeGraphQLError(w, http.StatusBadRequest, "X-User-Id must be a positive integer")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(store.WithUser(r.Context(), userID)))
+	})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// serveValidate checks a query against s the way /graphql would before
+// executing it, but runs no resolver. It takes the same bodies as
+// /graphql, or the query in a query parameter, and always answers with an
+// errors list, empty when the query is valid.
+func serveValidate(s graphql.Schema) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		req, err := readRequest(r)
+		switch {
+		case err == errUnsupportedMediaType:
+			writeGraphQLError(w, http.StatusUnsupportedMediaType, err.Error())
+			return
+		case isBodyTooLarge(err):
+			writeGraphQLError(w, http.StatusRequestEntityTooLarge, "request body too large")
+			return
+		case err != nil:
+			writeGraphQLError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		query := req.Query
+
+		errs := validateQuery(s, query)
+		addErrorCodes(errs)
+		if entry := requestLogFrom(r.Context()); entry != nil {
+			entry.record(query, len(errs) > 0)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": errs})
+	}
+}
+
+// validateQuery parses query and runs the spec's validation rules on it.
+func validateQuery(s graphql.Schema, query string) []gqlerrors.FormattedError {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return []gqlerrors.FormattedError{gqlerrors.FormatError(err)}
+	}
+
+	result := graphql.ValidateDocument(&s, doc, graphql.SpecifiedRules)
+	if result.Errors == nil {
+		return []gqlerrors.FormattedError{}
+	}
+	return result.Errors
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/nevzatalkan/golang-graphql-todo-example/graph"
+	"github.com/nevzatalkan/golang-graphql-todo-example/store"
+)
+
+func TestServeValidate(t *testing.T) {
+	s := store.NewMemoryStore()
+	h := serveValidate(newSchema(t, s))
+
+	for _, tc := range []struct {
+		query  string
+		errors int
+	}{
+		{`{ todoList { Id Text } }`, 0},
+		{`mutation { createTodo(Text: "only checked") { Id } }`, 0},
+		{`{ todoList { Id colour } }`, 1},
+		{`{ todo(Id: "one") { Id } }`, 1},
+		{`{ todoList { Id `, 1},
+	} {
+		r := newQueryPost(tc.query)
+		r.URL.Path = "/validate"
+		res := decodeResponse(t, serve(h, r))
+		if len(res.Errors) != tc.errors {
+			t.Errorf("%s: errors %+v, want %d", tc.query, res.Errors, tc.errors)
+			continue
+		}
+		for _, e := range res.Errors {
+			if e.Extensions["code"] != graph.CodeValidation {
+				t.Errorf("%s: error %+v, want it coded %s", tc.query, e, graph.CodeValidation)
+			}
+		}
+	}
+
+	w := serve(h, httptest.NewRequest(http.MethodGet, "/validate?query="+url.QueryEscape(`{ todoList { colour } }`), nil))
+	if res := decodeResponse(t, w); len(res.Errors) != 1 {
+		t.Errorf("GET: errors %+v, want 1", res.Errors)
+	}
+
+	// validating the mutation did not run it
+	if n, err := s.CountAll(context.Background()); err != nil || n != 0 {
+		t.Errorf("%d todos (err %v) after validating createTodo, want none", n, err)
+	}
+}